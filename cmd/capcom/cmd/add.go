@@ -9,7 +9,7 @@ import (
 	"github.com/poka-yoke/spaceflight/pkg/capcom"
 )
 
-var source, proto string
+var source, proto, ruleDescription string
 var port int64
 
 // addCmd represents the add command
@@ -20,29 +20,39 @@ var addCmd = &cobra.Command{
 This option adds a rule allowing inbound access to AWS
 machines pertaining to the selected security group (as
 sgid) from the specified source (as either CIDR or sgid
-string) to the specified port. E.g.:
+string) to the specified port, optionally described. E.g.:
 
-    capcom add --source 1.2.3.4/32 sg-abc01234`,
+    capcom add --source 1.2.3.4/32 sg-abc01234
+    capcom add --source 1.2.3.4/32 --description "Office VPN" sg-abc01234`,
 	Run: func(cmd *cobra.Command, args []string) {
-		svc := capcom.Init()
+		ctx := interruptible()
+		svc := target().Svc
 		for _, sgid := range args {
 			if !strings.HasPrefix(sgid, "sg-") {
 				log.Fatalf("%s is invalid SG id\n", sgid)
 			}
-			perm, err := capcom.BuildIPPermission(source, proto, port)
+			perm, err := capcom.BuildIPPermission(
+				source,
+				proto,
+				port,
+				ruleDescription,
+			)
 			if err != nil {
 				log.Fatal(err)
 			}
-			if !capcom.AuthorizeAccessToSecurityGroup(
+			err = capcom.AuthorizeAccessToSecurityGroup(
+				ctx,
 				svc,
 				perm,
 				sgid,
-			) {
-				log.Fatalf("Failed to add rule to %s: %s %s %d\n",
+			)
+			if err != nil {
+				log.Fatalf("Failed to add rule to %s: %s %s %d: %s\n",
 					sgid,
 					source,
 					proto,
 					port,
+					err,
 				)
 			}
 			log.Printf("Rule added successfully to %s: %s %s %d\n",
@@ -66,6 +76,7 @@ func init() {
 	addCmd.PersistentFlags().StringVarP(&source, "source", "s", "", "CIDR or sgid to be used as source of the Security Group Inbound rule")
 	addCmd.PersistentFlags().StringVarP(&proto, "proto", "", "tcp", "Which protocol will the rule affect to")
 	addCmd.PersistentFlags().Int64VarP(&port, "port", "p", 22, "Port for the rule")
+	addCmd.PersistentFlags().StringVarP(&ruleDescription, "description", "d", "", "Description for the rule")
 
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+
+	"github.com/olorin/nagiosplugin"
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/capcom"
+)
+
+var jsonOutput bool
+var sensitivePorts []int
+var maxPortRange int64
+
+// auditCmd represents the audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit [flags]",
+	Short: "Report risky Security Group rules",
+	Long: `
+This option inspects all Security Groups in your account and
+reports sensitive ports open to the world, unused groups,
+references to deleted groups, duplicate or shadowed rules,
+rules without description and overly broad port ranges.
+
+The exit code follows Nagios conventions: 2 (CRITICAL) when
+any sensitive port is open to the world, 1 (WARNING) for
+other risky findings and 0 (OK) otherwise. Findings from all
+the selected regions and accounts are aggregated. E.g.:
+
+    capcom audit --json --region all
+    capcom audit --sensitive-ports 22,5432 --max-port-range 100`,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := capcom.AuditOptions{MaxPortRange: maxPortRange}
+		for _, port := range sensitivePorts {
+			opts.SensitivePorts = append(opts.SensitivePorts, int64(port))
+		}
+		ctx := interruptible()
+		ts := targets()
+		findings := []capcom.Finding{}
+		for _, t := range ts {
+			list, err := capcom.Audit(ctx, t.Svc, opts)
+			if err != nil {
+				log.Fatal(err)
+			}
+			for _, f := range list {
+				if len(ts) > 1 {
+					f.Location = t.Location
+				}
+				findings = append(findings, f)
+			}
+		}
+		status, counts := auditStatus(findings)
+		if jsonOutput {
+			out, err := json.MarshalIndent(findings, "", "  ")
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println(string(out))
+			os.Exit(int(status))
+		}
+		for _, f := range findings {
+			fmt.Fprintln(os.Stderr, f)
+		}
+
+		check := nagiosplugin.NewCheck()
+		defer check.Finish()
+		for _, severity := range []capcom.Severity{
+			capcom.SeverityCritical,
+			capcom.SeverityWarning,
+			capcom.SeverityInfo,
+		} {
+			must(check.AddPerfDatum(
+				string(severity),
+				"",
+				float64(counts[severity]),
+				0.0,
+				math.Inf(1),
+			))
+		}
+		check.AddResultf(
+			status,
+			"%d critical, %d warning and %d info findings",
+			counts[capcom.SeverityCritical],
+			counts[capcom.SeverityWarning],
+			counts[capcom.SeverityInfo],
+		)
+	},
+}
+
+// auditStatus returns the Nagios status matching the most severe
+// finding, and the amount of findings per severity.
+func auditStatus(
+	findings []capcom.Finding,
+) (
+	status nagiosplugin.Status,
+	counts map[capcom.Severity]int,
+) {
+	status = nagiosplugin.OK
+	counts = make(map[capcom.Severity]int)
+	for _, f := range findings {
+		counts[f.Severity]++
+		switch {
+		case f.Severity == capcom.SeverityCritical:
+			status = nagiosplugin.CRITICAL
+		case f.Severity == capcom.SeverityWarning &&
+			status != nagiosplugin.CRITICAL:
+			status = nagiosplugin.WARNING
+		}
+	}
+	return
+}
+
+func init() {
+	RootCmd.AddCommand(auditCmd)
+
+	auditCmd.Flags().BoolVarP(
+		&jsonOutput,
+		"json",
+		"j",
+		false,
+		"Output findings in JSON format",
+	)
+	auditCmd.Flags().IntSliceVarP(
+		&sensitivePorts,
+		"sensitive-ports",
+		"",
+		nil,
+		"Ports which must not be open to the world (defaults to a list of well known services)",
+	)
+	auditCmd.Flags().Int64VarP(
+		&maxPortRange,
+		"max-port-range",
+		"",
+		capcom.DefaultMaxPortRange,
+		"Widest port range allowed in a rule",
+	)
+}
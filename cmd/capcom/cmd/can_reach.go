@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/capcom"
+)
+
+var from, to string
+
+// canReachCmd represents the can-reach command
+var canReachCmd = &cobra.Command{
+	Use:   "can-reach [flags]",
+	Short: "Check if an endpoint can reach another",
+	Long: `
+This option evaluates the ingress rules of the destination
+Security Groups and the egress rules of the source ones, if
+any, and explains which rules allow the traffic or why it is
+denied. The source can be a sgid, an instance id, a CIDR or
+an IP, and the destination either a sgid or an instance id.
+The exit code is 1 when traffic is denied. E.g.:
+
+    capcom can-reach --from sg-abc01234 --to i-0123456789abcdef --port 5432
+    capcom can-reach --from 10.0.0.0/24 --to sg-def01234 --port 443`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if from == "" || to == "" {
+			log.Fatal("Both --from and --to are mandatory")
+		}
+		svc := target().Svc
+		res, err := capcom.CheckReachability(interruptible(), svc, from, to, proto, port)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(res)
+		if !res.Allowed {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(canReachCmd)
+
+	canReachCmd.Flags().StringVarP(&from, "from", "f", "", "sgid, instance id, CIDR or IP originating the traffic")
+	canReachCmd.Flags().StringVarP(&to, "to", "t", "", "sgid or instance id receiving the traffic")
+	canReachCmd.Flags().StringVarP(&proto, "proto", "", "tcp", "Protocol of the traffic")
+	canReachCmd.Flags().Int64VarP(&port, "port", "p", 22, "Destination port of the traffic")
+}
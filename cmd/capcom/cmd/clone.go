@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/capcom"
+)
+
+var toVpc, toRegion, cloneName string
+var mappings []string
+
+// cloneCmd represents the clone command
+var cloneCmd = &cobra.Command{
+	Use:   "clone [flags] <sgid1> [[sgid2] [[...]]]",
+	Short: "Clone Security Groups into another VPC or region",
+	Long: `
+This option recreates the selected Security Groups, with all
+their rules and tags, in another VPC and, optionally, another
+region. References between the cloned groups point to their
+clones, while references to other groups, and to prefix lists
+when changing region, must be translated with --map or are
+skipped. If cloning fails, the clones created are deleted. The
+sgids of the clones are printed as old=new pairs. E.g.:
+
+    capcom clone --to-vpc vpc-12345678 sg-abc01234 sg-def01234
+    capcom clone --to-vpc vpc-12345678 --to-region eu-west-1 \
+        --map sg-0ld01234=sg-new01234 --name web-eu sg-abc01234`,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, sgid := range args {
+			if !strings.HasPrefix(sgid, "sg-") {
+				log.Fatalf("%s is invalid SG id\n", sgid)
+			}
+		}
+		if cloneName != "" && len(args) != 1 {
+			log.Fatal("--name can only be used when cloning a single group")
+		}
+		mapping, err := parseKeyValues(mappings)
+		if err != nil {
+			log.Fatal(err)
+		}
+		src := target()
+		dst := src
+		if toRegion != "" {
+			dst = src.WithRegion(toRegion)
+		}
+		res, err := capcom.CloneSecurityGroups(
+			interruptible(),
+			src.Svc,
+			dst.Svc,
+			args,
+			capcom.CloneOptions{
+				VpcID:       toVpc,
+				Name:        cloneName,
+				Mapping:     mapping,
+				CrossRegion: dst.Region != src.Region,
+			},
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, sgid := range args {
+			fmt.Printf("%s=%s\n", sgid, res.Mapping[sgid])
+		}
+		for _, rule := range res.Skipped {
+			log.Printf("Skipped rule %s\n", rule)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(cloneCmd)
+
+	cloneCmd.Flags().StringVarP(&toVpc, "to-vpc", "", "", "VPC ID where the clones should be created")
+	must(cloneCmd.MarkFlagRequired("to-vpc"))
+	cloneCmd.Flags().StringVarP(&toRegion, "to-region", "", "", "Region where the clones should be created (defaults to the source one)")
+	cloneCmd.Flags().StringVarP(&cloneName, "name", "", "", "Name for the clone, when cloning a single group")
+	cloneCmd.Flags().StringSliceVarP(&mappings, "map", "m", nil, "Translate references to a group not being cloned, as old=new (repeatable)")
+}
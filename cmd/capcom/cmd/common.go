@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/poka-yoke/spaceflight/pkg/capcom"
+)
+
+var regions, profiles, roles []string
+
+func must(err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parseKeyValues converts a list of key=value strings into a map.
+func parseKeyValues(list []string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, item := range list {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("%s is not a valid key=value pair", item)
+		}
+		out[parts[0]] = parts[1]
+	}
+	return out, nil
+}
+
+// interruptible returns a context which is cancelled when the command
+// gets interrupted or terminated, so AWS calls in flight are abandoned.
+func interruptible() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+	}()
+	return ctx
+}
+
+// targets returns a Target per account and region selected in the
+// command line.
+func targets() []capcom.Target {
+	out, err := capcom.NewTargets(capcom.TargetOptions{
+		Regions:  regions,
+		Profiles: profiles,
+		Roles:    roles,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	return out
+}
+
+// target returns the Target for commands working on a single account
+// and region.
+func target() capcom.Target {
+	out := targets()
+	if len(out) != 1 {
+		log.Fatalf(
+			"This command works on a single account and region, but %d were selected\n",
+			len(out),
+		)
+	}
+	return out[0]
+}
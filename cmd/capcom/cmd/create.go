@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"log"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -10,6 +11,7 @@ import (
 )
 
 var name, vpcid string
+var createTags []string
 
 // createCmd represents the create command
 var createCmd = &cobra.Command{
@@ -17,10 +19,26 @@ var createCmd = &cobra.Command{
 	Short: "Create a new Security Group",
 	Long: `Example:
     capcom create --name test This is a test SG
-    capcom create --name test --vpcid vpc-12345678 This is a test SG in a vpc`,
+    capcom create --name test --vpcid vpc-12345678 This is a test SG in a vpc
+    capcom create --name test --tag env=test --tag team=ops This is a tagged SG`,
 	Run: func(cmd *cobra.Command, args []string) {
-		svc := capcom.Init()
-		sgid := capcom.CreateSG(name, strings.Join(args, " "), vpcid, svc)
+		sgTags, err := parseKeyValues(createTags)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ctx := interruptible()
+		svc := target().Svc
+		sgid, err := capcom.CreateSG(
+			ctx,
+			name,
+			strings.Join(args, " "),
+			vpcid,
+			sgTags,
+			svc,
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
 		fmt.Println(sgid)
 	},
 }
@@ -47,6 +65,13 @@ func init() {
 		"",
 		"Name for the Security Group",
 	)
+	createCmd.PersistentFlags().StringSliceVarP(
+		&createTags,
+		"tag",
+		"t",
+		nil,
+		"Tag for the Security Group, as key=value (repeatable)",
+	)
 
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/capcom"
+)
+
+var exportFormat string
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export [flags]",
+	Short: "Dump Security Groups as JSON or Terraform",
+	Long: `
+This option dumps the Security Groups, with their tags and
+rules, in JSON format, which can be applied again with capcom
+import, or as Terraform aws_security_group and
+aws_security_group_rule resources. The Terraform output
+includes, as comments, the commands to import the existing
+groups into the Terraform state. Groups can be restricted by
+VPC, name and tags. E.g.:
+
+    capcom export --vpc vpc-12345678 > groups.json
+    capcom export --format terraform --tag env=production > groups.tf`,
+	Run: func(cmd *cobra.Command, args []string) {
+		tags, err := parseKeyValues(filterTags)
+		if err != nil {
+			log.Fatal(err)
+		}
+		export, err := capcom.ExportSecurityGroups(
+			interruptible(),
+			target().Svc,
+			capcom.GroupFilter{
+				VpcID: filterVpc,
+				Name:  filterName,
+				Tags:  tags,
+			},
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+		switch exportFormat {
+		case "json":
+			out, err := export.JSON()
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println(out)
+		case "terraform":
+			fmt.Print(export.Terraform())
+		default:
+			log.Fatalf("Unknown export format %s\n", exportFormat)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "json", "Export format: json or terraform")
+	exportCmd.Flags().StringVarP(&filterVpc, "vpc", "", "", "Only export Security Groups in this VPC")
+	exportCmd.Flags().StringVarP(&filterName, "name", "n", "", "Only export Security Groups matching this name (wildcards allowed)")
+	exportCmd.Flags().StringSliceVarP(&filterTags, "tag", "t", nil, "Only export Security Groups with this key=value tag (repeatable)")
+}
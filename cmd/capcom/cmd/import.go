@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/capcom"
+)
+
+var importVpc string
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import [flags] <file>",
+	Short: "Apply Security Groups dumped by capcom export",
+	Long: `
+This option applies a JSON file produced by capcom export.
+Groups are matched by name and VPC and created when missing,
+and the rules missing in them are added. Groups exported
+without a VPC need --to-vpc. Rules not present in
+the file are left untouched, so importing the same file twice
+is safe. References between the imported groups point to the
+matching groups. The sgids used are printed as old=new pairs.
+E.g.:
+
+    capcom import groups.json
+    capcom import --to-vpc vpc-87654321 groups.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			log.Fatal("A single file to import is required")
+		}
+		data, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		export, err := capcom.ParseExport(data)
+		if err != nil {
+			log.Fatal(err)
+		}
+		res, err := capcom.ImportSecurityGroups(
+			interruptible(),
+			target().Svc,
+			export,
+			capcom.ImportOptions{VpcID: importVpc},
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, rule := range res.Added {
+			log.Printf("Added rule %s\n", rule)
+		}
+		for _, group := range export.Groups {
+			fmt.Printf("%s=%s\n", group.GroupID, res.Mapping[group.GroupID])
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVarP(&importVpc, "to-vpc", "", "", "VPC ID where the groups should be applied (defaults to the exported one)")
+}
@@ -9,7 +9,9 @@ import (
 	"github.com/poka-yoke/spaceflight/pkg/capcom"
 )
 
-var graph, search bool
+var graph, search, attachments bool
+var graphFormat, filterVpc, filterName string
+var filterTags []string
 
 // listCmd represents the list command
 var listCmd = &cobra.Command{
@@ -17,22 +19,84 @@ var listCmd = &cobra.Command{
 	Short: "Show all Security Groups",
 	Long: `
 This option shows a information about the Security groups
-present in your account. The information is shown as a list
-but can also be presented in dot format for graphics processing.`,
+present in your account, including their tags. The
+information is shown as a list but can also be presented as
+a graph for graphics processing, in DOT, Mermaid or JSON
+format. Graphs include the CIDRs and prefix lists referenced
+by the rules, with egress edges dashed. Lists, searches and
+graphs can be restricted by VPC, name and tags. Lists and
+searches aggregate all the selected regions and accounts,
+while graphs work on a single one. E.g.:
+
+    capcom list --graph --format mermaid --vpc vpc-12345678
+    capcom list --graph --name 'web-*' --tag env=production
+    capcom list --search 10.0.0.0/8 --region all --profile prod --profile staging
+    capcom list --search 1.2.3.4/32 --attachments`,
 	Run: func(cmd *cobra.Command, args []string) {
-		svc := capcom.Init()
+		tags, err := parseKeyValues(filterTags)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ctx := interruptible()
+		filter := capcom.GroupFilter{
+			VpcID: filterVpc,
+			Name:  filterName,
+			Tags:  tags,
+		}
 		if graph {
-			fmt.Print(capcom.GraphSGRelations(svc))
-		} else if search {
-			list, err := capcom.FindSecurityGroupsWithRange(svc, args[0])
+			g, err := capcom.BuildGraph(ctx, target().Svc, filter)
 			if err != nil {
-				log.Fatal(err.Error())
+				log.Fatal(err)
 			}
-			for _, l := range list {
-				fmt.Println(l)
+			switch graphFormat {
+			case "dot":
+				fmt.Print(g.DOT())
+			case "mermaid":
+				fmt.Print(g.Mermaid())
+			case "json":
+				out, err := g.JSON()
+				if err != nil {
+					log.Fatal(err)
+				}
+				fmt.Print(out)
+			default:
+				log.Fatalf("Unknown graph format %s\n", graphFormat)
+			}
+			return
+		}
+		ts := targets()
+		for _, t := range ts {
+			if search {
+				list, err := capcom.FindFilteredSecurityGroupsWithRange(
+					ctx,
+					t.Svc,
+					args[0],
+					filter,
+				)
+				if err != nil {
+					log.Fatal(err.Error())
+				}
+				if attachments {
+					if err := capcom.AddAttachments(ctx, t.Svc, t.RDS, list); err != nil {
+						log.Fatal(err)
+					}
+				}
+				for _, l := range list {
+					if len(ts) > 1 {
+						l.Location = t.Location
+					}
+					fmt.Println(l)
+				}
+			} else {
+				if len(ts) > 1 {
+					fmt.Printf("# %s\n", t.Location)
+				}
+				list, err := capcom.ListFilteredSecurityGroups(ctx, t.Svc, filter)
+				if err != nil {
+					log.Fatal(err)
+				}
+				fmt.Print(list)
 			}
-		} else {
-			fmt.Print(capcom.ListSecurityGroups(svc))
 		}
 	},
 }
@@ -49,7 +113,12 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// listCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
-	listCmd.Flags().BoolVarP(&graph, "graph", "g", false, "Output relations as a graph in DOT format")
+	listCmd.Flags().BoolVarP(&graph, "graph", "g", false, "Output relations as a graph")
 	listCmd.Flags().BoolVarP(&search, "search", "s", false, "Search for this following CIDR in all SGs")
+	listCmd.Flags().BoolVarP(&attachments, "attachments", "a", false, "Show the network interfaces exposed by each search result")
+	listCmd.Flags().StringVarP(&graphFormat, "format", "f", "dot", "Graph format: dot, mermaid or json")
+	listCmd.Flags().StringVarP(&filterVpc, "vpc", "", "", "Only show Security Groups in this VPC")
+	listCmd.Flags().StringVarP(&filterName, "name", "n", "", "Only show Security Groups matching this name (wildcards allowed)")
+	listCmd.Flags().StringSliceVarP(&filterTags, "tag", "t", nil, "Only show Security Groups with this key=value tag (repeatable)")
 
 }
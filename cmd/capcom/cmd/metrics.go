@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/capcom"
+)
+
+var listenAddress string
+
+// metricsCmd represents the metrics command
+var metricsCmd = &cobra.Command{
+	Use:   "metrics [flags]",
+	Short: "Export Security Group posture metrics to Prometheus",
+	Long: `
+This option exports Prometheus metrics on the posture of the
+Security Groups: rules per group and direction, ingress rules
+opening sensitive ports to the world, unused groups and
+instances per group and state. Metrics are pushed once to a
+Pushgateway, or served on /metrics when listening. E.g.:
+
+    capcom metrics --push-gateway http://pushgateway:9091
+    capcom metrics --listen :8080 --sensitive-ports 22,5432`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var ports []int64
+		for _, port := range sensitivePorts {
+			ports = append(ports, int64(port))
+		}
+		collector := capcom.NewCollector(target().Svc, ports)
+		if pgaddress != "" {
+			err := push.New(pgaddress, "capcom").
+				Collector(collector).Push()
+			if err != nil {
+				log.Fatal("Could not push metrics to Pushgateway: ", err)
+			}
+			return
+		}
+		if listenAddress == "" {
+			log.Fatal("Either --push-gateway or --listen is required")
+		}
+		prometheus.MustRegister(collector)
+		http.Handle("/metrics", promhttp.Handler())
+		log.Fatal(http.ListenAndServe(listenAddress, nil))
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(metricsCmd)
+
+	metricsCmd.Flags().StringVarP(&pgaddress, "push-gateway", "p", "", "Address of the Prometheus Pushgateway to push metrics to")
+	metricsCmd.Flags().StringVarP(&listenAddress, "listen", "l", "", "Address to serve metrics on, e.g. :8080")
+	metricsCmd.Flags().IntSliceVarP(&sensitivePorts, "sensitive-ports", "", nil, "Ports to count world open rules for (defaults to a list of well known services)")
+}
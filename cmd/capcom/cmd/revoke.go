@@ -21,25 +21,28 @@ string) to the specified port. E.g.:
 
     capcom revoke --source 1.2.3.4/32 sg-abc01234`,
 	Run: func(cmd *cobra.Command, args []string) {
-		svc := capcom.Init()
+		ctx := interruptible()
+		svc := target().Svc
 		for _, sgid := range args {
 			if !strings.HasPrefix(sgid, "sg-") {
 				log.Fatalf("%s is invalid SG id\n", sgid)
 			}
-			perm, err := capcom.BuildIPPermission(source, proto, port)
+			perm, err := capcom.BuildIPPermission(source, proto, port, "")
 			if err != nil {
 				log.Fatal(err)
 			}
-			if !capcom.RevokeAccessToSecurityGroup(
+			if err := capcom.RevokeAccessToSecurityGroup(
+				ctx,
 				svc,
 				perm,
 				sgid,
-			) {
-				log.Fatalf("Failed to remove rule to %s: %s %s %d\n",
+			); err != nil {
+				log.Fatalf("Failed to remove rule to %s: %s %s %d: %s\n",
 					sgid,
 					source,
 					proto,
 					port,
+					err,
 				)
 			}
 			log.Printf("Rule removed successfully to %s: %s %s %d\n",
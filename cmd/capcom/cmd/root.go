@@ -6,6 +6,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"github.com/poka-yoke/spaceflight/pkg/capcom"
 )
 
 var cfgFile string
@@ -42,6 +44,27 @@ func init() {
 	// will be global for your application.
 
 	//RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.capcom.yaml)")
+	RootCmd.PersistentFlags().StringSliceVarP(
+		&regions,
+		"region",
+		"r",
+		[]string{capcom.DefaultRegion},
+		"AWS regions to work on, or 'all' (repeatable)",
+	)
+	RootCmd.PersistentFlags().StringSliceVarP(
+		&profiles,
+		"profile",
+		"",
+		nil,
+		"AWS shared config profiles to use, one per account (repeatable)",
+	)
+	RootCmd.PersistentFlags().StringSliceVarP(
+		&roles,
+		"role-arn",
+		"",
+		nil,
+		"IAM roles to assume, one per account (repeatable)",
+	)
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	//RootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
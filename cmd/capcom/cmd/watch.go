@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/capcom"
+)
+
+var watchInterval time.Duration
+var webhook, pgaddress string
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch [flags]",
+	Short: "Report changes in Security Group rules",
+	Long: `
+This option takes a snapshot of the Security Groups every
+interval and reports the rules added or removed since the
+previous one, to detect changes made outside capcom. Each
+change in a group is reported as a JSON event, with the rules
+added and removed and a diff, to stdout unless a webhook or a
+Prometheus Pushgateway is given. Groups can be restricted by
+VPC, name and tags. E.g.:
+
+    capcom watch --interval 5m
+    capcom watch --webhook https://hooks.example.com/capcom --vpc vpc-12345678
+    capcom watch --push-gateway http://pushgateway:9091`,
+	Run: func(cmd *cobra.Command, args []string) {
+		tags, err := parseKeyValues(filterTags)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var sinks capcom.MultiSink
+		if webhook != "" {
+			sinks = append(sinks, capcom.WebhookSink{URL: webhook})
+		}
+		if pgaddress != "" {
+			sinks = append(sinks, capcom.NewPushgatewaySink(pgaddress))
+		}
+		if len(sinks) == 0 {
+			sinks = append(sinks, capcom.JSONSink{Writer: os.Stdout})
+		}
+		t := target()
+		w := &capcom.Watcher{
+			Client: capcom.NewClient(t.Svc),
+			Filter: capcom.GroupFilter{
+				VpcID: filterVpc,
+				Name:  filterName,
+				Tags:  tags,
+			},
+			Location: t.Location,
+		}
+
+		ctx := interruptible()
+		log.Printf("Watching Security Groups every %s\n", watchInterval)
+		if err := w.Run(ctx, watchInterval, sinks); err != context.Canceled {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().DurationVarP(&watchInterval, "interval", "i", 5*time.Minute, "Time between snapshots")
+	watchCmd.Flags().StringVarP(&webhook, "webhook", "w", "", "URL to POST each change to, in JSON format")
+	watchCmd.Flags().StringVarP(&pgaddress, "push-gateway", "p", "", "Address of the Prometheus Pushgateway to push change counters to")
+	watchCmd.Flags().StringVarP(&filterVpc, "vpc", "", "", "Only watch Security Groups in this VPC")
+	watchCmd.Flags().StringVarP(&filterName, "name", "n", "", "Only watch Security Groups matching this name (wildcards allowed)")
+	watchCmd.Flags().StringSliceVarP(&filterTags, "tag", "t", nil, "Only watch Security Groups with this key=value tag (repeatable)")
+}
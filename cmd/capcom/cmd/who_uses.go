@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/capcom"
+)
+
+// whoUsesCmd represents the who-uses command
+var whoUsesCmd = &cobra.Command{
+	Use:   "who-uses [flags] <sgid1> [[sgid2] [[...]]]",
+	Short: "Show what is attached to Security Groups",
+	Long: `
+This option lists the network interfaces attached to the
+selected Security Groups, with the instance, RDS instance,
+load balancer or Lambda function they belong to and their
+private and public IPs. Use it to know what will lose access
+before revoking a rule. E.g.:
+
+    capcom who-uses sg-abc01234
+    capcom who-uses --json sg-abc01234 sg-def01234`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := interruptible()
+		t := target()
+		usage := make(map[string][]capcom.Attachment)
+		for _, sgid := range args {
+			if !strings.HasPrefix(sgid, "sg-") {
+				log.Fatalf("%s is invalid SG id\n", sgid)
+			}
+			attachments, err := capcom.FindAttachments(ctx, t.Svc, t.RDS, sgid)
+			if err != nil {
+				log.Fatal(err)
+			}
+			usage[sgid] = attachments
+		}
+		if jsonOutput {
+			out, err := json.MarshalIndent(usage, "", "  ")
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println(string(out))
+			return
+		}
+		for _, sgid := range args {
+			fmt.Printf("%s:\n", sgid)
+			for _, a := range usage[sgid] {
+				fmt.Printf("    %s\n", a)
+			}
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(whoUsesCmd)
+
+	whoUsesCmd.Flags().BoolVarP(
+		&jsonOutput,
+		"json",
+		"j",
+		false,
+		"Output attachments in JSON format",
+	)
+}
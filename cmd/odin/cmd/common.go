@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"log"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/rds"
 	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+	"github.com/spf13/cobra"
 
 	"github.com/poka-yoke/spaceflight/pkg/odin"
 )
@@ -54,6 +58,36 @@ func waitForInstance(
 	return
 }
 
+// waitForSnapshot polls snapshot until it is available, printing its
+// progress.
+func waitForSnapshot(
+	snapshot *rds.DBSnapshot,
+	svc rdsiface.RDSAPI,
+	duration time.Duration,
+) (err error) {
+	for aws.StringValue(snapshot.Status) != "available" {
+		if aws.StringValue(snapshot.Status) == "failed" {
+			return fmt.Errorf(
+				"Snapshot %s failed",
+				*snapshot.DBSnapshotIdentifier,
+			)
+		}
+		fmt.Printf(
+			"%s %s %d%%\n",
+			*snapshot.DBSnapshotIdentifier,
+			aws.StringValue(snapshot.Status),
+			aws.Int64Value(snapshot.PercentProgress),
+		)
+		// This is to avoid AWS API rate throttling.
+		time.Sleep(duration)
+		snapshot, err = odin.GetSnapshot(*snapshot.DBSnapshotIdentifier, svc)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
 // modifyInstance enqueues a modify operation
 func modifyInstance(
 	params odin.ModifiableParams,
@@ -67,3 +101,140 @@ func modifyInstance(
 	_, err = svc.ModifyDBInstance(rdsParams)
 	return err
 }
+
+var engine, engineVersion, parameterGroup, optionGroup string
+var storageType, kmsKeyID, maintenanceWindow string
+var iops, backupRetention int64
+var multiAZ, encrypted bool
+
+// addInstanceOptionsFlags adds the flags configuring the engine,
+// storage, availability and maintenance of instances to cmd. Restores
+// inherit the engine version and encryption from the snapshot.
+func addInstanceOptionsFlags(cmd *cobra.Command, restore bool) {
+	cmd.PersistentFlags().StringVarP(
+		&engine,
+		"engine",
+		"e",
+		"",
+		"Engine to use, like postgres or mysql (default postgres, or the snapshot's)",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&parameterGroup,
+		"parameter-group",
+		"",
+		"",
+		"DB Parameter Group to use",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&optionGroup,
+		"option-group",
+		"",
+		"",
+		"Option Group to use",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&storageType,
+		"storage-type",
+		"",
+		"",
+		"Storage type: standard, gp2 or io1",
+	)
+	cmd.PersistentFlags().Int64VarP(
+		&iops,
+		"iops",
+		"",
+		0,
+		"Provisioned IOPS, for io1 storage",
+	)
+	cmd.PersistentFlags().BoolVarP(
+		&multiAZ,
+		"multi-az",
+		"",
+		false,
+		"Deploy a standby replica in another Availability Zone",
+	)
+	cmd.PersistentFlags().Int64VarP(
+		&backupRetention,
+		"backup-retention",
+		"",
+		0,
+		"Days to keep automated backups, up to 35 (default RDS's)",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&maintenanceWindow,
+		"maintenance-window",
+		"",
+		"",
+		"Weekly maintenance window, like sun:05:00-sun:06:00",
+	)
+	if restore {
+		return
+	}
+	cmd.PersistentFlags().StringVarP(
+		&engineVersion,
+		"engine-version",
+		"",
+		"",
+		"Engine version to use (default RDS's, or the snapshot's)",
+	)
+	cmd.PersistentFlags().BoolVarP(
+		&encrypted,
+		"encrypted",
+		"",
+		false,
+		"Encrypt the storage",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&kmsKeyID,
+		"kms-key-id",
+		"",
+		"",
+		"KMS key to encrypt the storage with (default RDS's)",
+	)
+}
+
+// setInstanceOptions sets the options in the command line to params.
+func setInstanceOptions(params *odin.Instance) {
+	params.Engine = engine
+	params.EngineVersion = engineVersion
+	params.ParameterGroupName = parameterGroup
+	params.OptionGroupName = optionGroup
+	params.StorageType = storageType
+	params.IOPS = iops
+	params.MultiAZ = multiAZ
+	params.Encrypted = encrypted
+	params.KMSKeyID = kmsKeyID
+	params.BackupRetentionPeriod = backupRetention
+	params.MaintenanceWindow = maintenanceWindow
+}
+
+var outputFormat string
+
+// addOutputFlag adds the flag choosing the output format to cmd.
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVarP(
+		&outputFormat,
+		"output",
+		"o",
+		"table",
+		"Output format: table or json",
+	)
+}
+
+// checkOutputFormat returns the output format, failing if it is not
+// supported.
+func checkOutputFormat() string {
+	if outputFormat != "table" && outputFormat != "json" {
+		log.Fatalf("Error: Unknown output format %s", outputFormat)
+	}
+	return outputFormat
+}
+
+// printJSON prints v as indented JSON.
+func printJSON(v interface{}) {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		log.Fatalf("Error: %s", err)
+	}
+	fmt.Println(string(out))
+}
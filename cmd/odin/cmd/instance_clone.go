@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/spf13/cobra"
 
 	"github.com/poka-yoke/spaceflight/pkg/odin"
@@ -36,10 +37,17 @@ var instanceCloneCmd = &cobra.Command{
 			Size:                 size,
 			OriginalInstanceName: from,
 		}
+		setInstanceOptions(&params)
 		rdsPar, err := params.CloneDBInput(svc)
 		if err != nil {
 			log.Fatalf("Error: %s", err)
 		}
+		// Check the engine the clone gets from the snapshot, unless given.
+		checked := params
+		checked.Engine = aws.StringValue(rdsPar.Engine)
+		if err := checked.CheckEngine(svc); err != nil {
+			log.Fatalf("Error: %s", err)
+		}
 		res, err := svc.CreateDBInstance(rdsPar)
 		if err != nil {
 			log.Fatalf("Error: %s", err)
@@ -62,6 +70,7 @@ var instanceCloneCmd = &cobra.Command{
 
 func init() {
 	InstanceCmd.AddCommand(instanceCloneCmd)
+	addInstanceOptionsFlags(instanceCloneCmd, false)
 
 	// Here you will define your flags and configuration settings.
 
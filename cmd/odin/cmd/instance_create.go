@@ -33,10 +33,14 @@ var instanceCreateCmd = &cobra.Command{
 			SecurityGroups:  strings.Split(securityGroups, ","),
 			Size:            size,
 		}
+		setInstanceOptions(&params)
 		rdsParams, err := params.CreateDBInput()
 		if err != nil {
 			log.Fatalf("Error: %s", err)
 		}
+		if err := params.CheckEngine(svc); err != nil {
+			log.Fatalf("Error: %s", err)
+		}
 		res, err := svc.CreateDBInstance(rdsParams)
 		if err != nil {
 			log.Fatalf("Error: %s", err)
@@ -59,6 +63,7 @@ var instanceCreateCmd = &cobra.Command{
 
 func init() {
 	InstanceCmd.AddCommand(instanceCreateCmd)
+	addInstanceOptionsFlags(instanceCreateCmd, false)
 
 	// Here you will define your flags and configuration settings.
 
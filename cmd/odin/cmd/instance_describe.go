@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/odin"
+)
+
+// instanceDescribeCmd shows the details of an instance
+var instanceDescribeCmd = &cobra.Command{
+	Use:   "describe [flags] identifier",
+	Short: "Describes a database instance",
+	Long:  `Describes a database instance in RDS, with its latest snapshot.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			log.Fatal(InstanceIDReq)
+		}
+		format := checkOutputFormat()
+		svc := rdsLogin("us-east-1")
+		i, err := odin.DescribeInstance(args[0], svc)
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		if format == "json" {
+			printJSON(i)
+			return
+		}
+		endpoint := ""
+		if i.Endpoint != "" {
+			endpoint = fmt.Sprintf("%s:%d", i.Endpoint, i.Port)
+		}
+		storage := fmt.Sprintf("%d GB %s", i.Storage, i.StorageType)
+		if i.IOPS != 0 {
+			storage = fmt.Sprintf("%s, %d IOPS", storage, i.IOPS)
+		}
+		if i.Encrypted {
+			storage += ", encrypted"
+		}
+		snapshot := ""
+		if i.LatestSnapshot != nil {
+			snapshot = fmt.Sprintf(
+				"%s %s %s",
+				i.LatestSnapshot.Identifier,
+				i.LatestSnapshot.Created,
+				i.LatestSnapshot.Status,
+			)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, row := range [][]string{
+			{"Identifier", i.Identifier},
+			{"Status", i.Status},
+			{"Engine", i.Engine + " " + i.EngineVersion},
+			{"Class", i.Class},
+			{"Endpoint", endpoint},
+			{"Storage", storage},
+			{"Multi-AZ", fmt.Sprint(i.MultiAZ)},
+			{"Pending modifications", formatMap(i.PendingModifications)},
+			{"Parameter groups", formatMap(i.ParameterGroups)},
+			{
+				"Backup window",
+				fmt.Sprintf("%s, %d days retention", i.BackupWindow, i.BackupRetention),
+			},
+			{"Maintenance window", i.MaintenanceWindow},
+			{"Tags", formatMap(i.Tags)},
+			{"Latest snapshot", snapshot},
+		} {
+			fmt.Fprintf(w, "%s:\t%s\n", row[0], row[1])
+		}
+		w.Flush()
+	},
+}
+
+// formatMap returns the names and values in m, sorted by name.
+func formatMap(m map[string]string) string {
+	names := []string{}
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for n, name := range names {
+		names[n] = fmt.Sprintf("%s=%s", name, m[name])
+	}
+	return strings.Join(names, " ")
+}
+
+func init() {
+	InstanceCmd.AddCommand(instanceDescribeCmd)
+	addOutputFlag(instanceDescribeCmd)
+}
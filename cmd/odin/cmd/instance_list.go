@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/odin"
+)
+
+var listFilter odin.InstanceFilter
+var listTags []string
+
+// instanceListCmd lists the instances passing the filters
+var instanceListCmd = &cobra.Command{
+	Use:   "list [flags]",
+	Short: "Lists database instances",
+	Long:  `Lists database instances in RDS, filtered by engine, status, class or tags.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format := checkOutputFormat()
+		listFilter.Tags = map[string]string{}
+		for _, tag := range listTags {
+			parts := strings.SplitN(tag, "=", 2)
+			if len(parts) == 1 {
+				parts = append(parts, "")
+			}
+			listFilter.Tags[parts[0]] = parts[1]
+		}
+		svc := rdsLogin("us-east-1")
+		instances, err := odin.ListInstances(listFilter, svc)
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		if format == "json" {
+			printJSON(instances)
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "IDENTIFIER\tENGINE\tCLASS\tSTATUS\tSTORAGE\tMULTI-AZ\tENDPOINT")
+		for _, i := range instances {
+			fmt.Fprintf(
+				w,
+				"%s\t%s %s\t%s\t%s\t%d GB %s\t%v\t%s\n",
+				i.Identifier,
+				i.Engine,
+				i.EngineVersion,
+				i.Class,
+				i.Status,
+				i.Storage,
+				i.StorageType,
+				i.MultiAZ,
+				i.Endpoint,
+			)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	InstanceCmd.AddCommand(instanceListCmd)
+
+	instanceListCmd.PersistentFlags().StringVarP(
+		&listFilter.Engine,
+		"engine",
+		"e",
+		"",
+		"Only list instances with this engine",
+	)
+	instanceListCmd.PersistentFlags().StringVarP(
+		&listFilter.Status,
+		"status",
+		"s",
+		"",
+		"Only list instances in this status, like available",
+	)
+	instanceListCmd.PersistentFlags().StringVarP(
+		&listFilter.Class,
+		"instance-type",
+		"t",
+		"",
+		"Only list instances of this type",
+	)
+	instanceListCmd.PersistentFlags().StringSliceVarP(
+		&listTags,
+		"tag",
+		"T",
+		[]string{},
+		"Only list instances with this tag, as key or key=value",
+	)
+	addOutputFlag(instanceListCmd)
+}
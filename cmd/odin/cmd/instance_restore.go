@@ -6,6 +6,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
 	"github.com/spf13/cobra"
 
 	"github.com/poka-yoke/spaceflight/pkg/odin"
@@ -15,7 +17,9 @@ import (
 var instanceRestoreCmd = &cobra.Command{
 	Use:   "restore [flags] identifier",
 	Short: "Restores from a Snapshot to a database",
-	Long:  `Restores from a Snapshot to a database, in RDS.`,
+	Long: `Restores from a Snapshot to a database, in RDS, or from the
+automated backups of the original instance to a point in time, with
+--to-time or --latest.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) != 1 {
 			log.Fatal(NewInstanceIDReq)
@@ -29,18 +33,25 @@ var instanceRestoreCmd = &cobra.Command{
 			SecurityGroups:       securityGroupsList,
 			OriginalInstanceName: from,
 		}
-		rdsParams, err := params.RestoreDBInput(
-			svc,
-		)
-		if err != nil {
-			log.Fatalf("Error: %s", err)
+		setInstanceOptions(&params)
+		var instance *rds.DBInstance
+		if toTime != "" || latest {
+			instance = restoreToPointInTime(params, svc)
+		} else {
+			rdsParams, err := params.RestoreDBInput(
+				svc,
+			)
+			if err != nil {
+				log.Fatalf("Error: %s", err)
+			}
+			res, err := svc.RestoreDBInstanceFromDBSnapshot(rdsParams)
+			if err != nil {
+				log.Fatalf("Error: %s", err)
+			}
+			instance = res.DBInstance
 		}
-		res, err := svc.RestoreDBInstanceFromDBSnapshot(rdsParams)
-		if err != nil {
-			log.Fatalf("Error: %s", err)
-		}
-		err = waitForInstance(
-			res.DBInstance,
+		err := waitForInstance(
+			instance,
 			svc,
 			"available",
 			5*time.Second,
@@ -51,12 +62,43 @@ var instanceRestoreCmd = &cobra.Command{
 		if err := modifyInstance(params, svc, false); err != nil {
 			log.Fatalf("Error: %s", err)
 		}
-		fmt.Println(*res.DBInstance.Endpoint.Address)
+		fmt.Println(*instance.Endpoint.Address)
 	},
 }
 
+var toTime string
+var latest bool
+
+// restoreToPointInTime starts restoring the original instance of
+// params to the time in --to-time, or the latest restorable time.
+func restoreToPointInTime(
+	params odin.Instance,
+	svc rdsiface.RDSAPI,
+) *rds.DBInstance {
+	var restoreTime time.Time
+	switch {
+	case toTime != "" && latest:
+		log.Fatal("Error: --to-time and --latest are mutually exclusive")
+	case toTime != "":
+		var err error
+		if restoreTime, err = time.Parse(time.RFC3339, toTime); err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+	}
+	rdsParams, err := params.PointInTimeRestoreDBInput(restoreTime, svc)
+	if err != nil {
+		log.Fatalf("Error: %s", err)
+	}
+	res, err := svc.RestoreDBInstanceToPointInTime(rdsParams)
+	if err != nil {
+		log.Fatalf("Error: %s", err)
+	}
+	return res.DBInstance
+}
+
 func init() {
 	InstanceCmd.AddCommand(instanceRestoreCmd)
+	addInstanceOptionsFlags(instanceRestoreCmd, true)
 
 	// Here you will define your flags and configuration settings.
 
@@ -75,7 +117,7 @@ func init() {
 		"from",
 		"f",
 		"",
-		"RDS Instance to look for snapshot",
+		"RDS Instance to restore, or to look for snapshot",
 	)
 	instanceRestoreCmd.PersistentFlags().StringVarP(
 		&subnetName,
@@ -91,6 +133,20 @@ func init() {
 		"",
 		"VPC SG IDs separated to attach to (effectively VPC)",
 	)
+	instanceRestoreCmd.PersistentFlags().StringVarP(
+		&toTime,
+		"to-time",
+		"",
+		"",
+		"Restore the original instance as it was at this RFC3339 time",
+	)
+	instanceRestoreCmd.PersistentFlags().BoolVarP(
+		&latest,
+		"latest",
+		"",
+		false,
+		"Restore the original instance at its latest restorable time",
+	)
 
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
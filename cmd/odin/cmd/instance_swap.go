@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/odin"
+)
+
+// instanceSwapCmd represents the instance swap command
+var instanceSwapCmd = &cobra.Command{
+	Use:   "swap identifier identifier",
+	Short: "Swaps the names of two databases",
+	Long: `Swaps the names, and so the endpoints, of two databases in
+RDS, undoing the renames done if any fails. E.g.:
+
+    odin instance swap production-rds production-rds-upgraded`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			log.Fatal("You must specify the identifiers of the two instances")
+		}
+		svc := rdsLogin("us-east-1")
+		temporary := odin.SuffixedIdentifier(
+			args[0],
+			"swap-"+time.Now().UTC().Format("20060102150405"),
+		)
+		err := odin.SwapInstances(args[0], args[1], temporary, svc, 5*time.Second)
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		fmt.Printf("%s and %s were swapped\n", args[0], args[1])
+	},
+}
+
+func init() {
+	InstanceCmd.AddCommand(instanceSwapCmd)
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+	_ "github.com/lib/pq"
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/odin"
+)
+
+var sanitizeScript, database string
+
+// refreshCmd represents the refresh command
+var refreshCmd = &cobra.Command{
+	Use:   "refresh [flags] identifier",
+	Short: "Refreshes a database from another one's last snapshot",
+	Long: `Restores the last snapshot of an instance as a new one, runs a
+sanitization script in it, and replaces the instance with the identifier
+by it, deleting the old one. Only PostgreSQL can be sanitized. The copy
+is restored from the snapshot, like "instance restore" does, rather than
+cloned, as "instance clone" creates an empty instance with the settings
+of the snapshot, without its data. E.g.:
+
+    odin refresh staging-db --from prod-db --sanitize sanitize.sql -u owner -p secret`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			log.Fatal(InstanceIDReq)
+		}
+		if sanitizeScript == "" {
+			log.Fatal("Error: You must specify a sanitization script")
+		}
+		script, err := ioutil.ReadFile(sanitizeScript)
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		svc := rdsLogin("us-east-1")
+		suffix := time.Now().UTC().Format("20060102150405")
+		fresh := odin.SuffixedIdentifier(args[0], suffix)
+		retired := odin.SuffixedIdentifier(args[0], "old-"+suffix)
+		params := odin.Instance{
+			Identifier:           fresh,
+			Type:                 instanceType,
+			SubnetGroupName:      subnetName,
+			SecurityGroups:       strings.Split(securityGroups, ","),
+			OriginalInstanceName: from,
+		}
+		setInstanceOptions(&params)
+		rdsParams, err := params.RestoreDBInput(svc)
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		res, err := svc.RestoreDBInstanceFromDBSnapshot(rdsParams)
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		err = waitForInstance(res.DBInstance, svc, "available", 5*time.Second)
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		// Security groups are needed right away to connect and sanitize.
+		if err := modifyInstance(params, svc, true); err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		err = odin.WaitForModification(fresh, svc, 5*time.Second)
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		fmt.Printf("Sanitizing %s\n", fresh)
+		if err := sanitize(fresh, string(script), svc); err != nil {
+			// Unsanitized data must not be left around.
+			deleteInstance(odin.Instance{Identifier: fresh}, svc)
+			log.Fatalf("Error: %s", err)
+		}
+		replaced, err := odin.ReplaceInstance(
+			args[0],
+			fresh,
+			retired,
+			svc,
+			5*time.Second,
+		)
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		if replaced {
+			deleteInstance(
+				odin.Instance{
+					Identifier:      retired,
+					FinalSnapshotID: finalSnapshotID,
+				},
+				svc,
+			)
+		}
+		fmt.Printf("%s was refreshed from %s\n", args[0], from)
+	},
+}
+
+// sanitize runs script in the database of the instance with the id.
+func sanitize(id string, script string, svc rdsiface.RDSAPI) error {
+	output, err := svc.DescribeDBInstances(
+		&rds.DescribeDBInstancesInput{
+			DBInstanceIdentifier: aws.String(id),
+		},
+	)
+	if err != nil {
+		return err
+	}
+	connection, err := odin.ConnectionString(
+		output.DBInstances[0],
+		user,
+		password,
+		database,
+	)
+	if err != nil {
+		return err
+	}
+	db, err := sql.Open("postgres", connection)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return odin.Sanitize(db, script)
+}
+
+// deleteInstance deletes the instance of params, waiting until it is
+// gone.
+func deleteInstance(params odin.Instance, svc rdsiface.RDSAPI) {
+	rdsParams, err := params.DeleteDBInput()
+	if err != nil {
+		log.Fatalf("Error: %s", err)
+	}
+	if _, err := svc.DeleteDBInstance(rdsParams); err != nil {
+		log.Fatalf("Error: %s", err)
+	}
+	err = odin.WaitForStatus(params.Identifier, "", svc, 5*time.Second)
+	if err != nil {
+		log.Fatalf("Error: %s", err)
+	}
+	fmt.Printf("%s instance was deleted\n", params.Identifier)
+}
+
+func init() {
+	RootCmd.AddCommand(refreshCmd)
+	addInstanceOptionsFlags(refreshCmd, true)
+
+	refreshCmd.PersistentFlags().StringVarP(
+		&from,
+		"from",
+		"f",
+		"",
+		"RDS Instance to restore the last snapshot of",
+	)
+	refreshCmd.PersistentFlags().StringVarP(
+		&sanitizeScript,
+		"sanitize",
+		"S",
+		"",
+		"SQL script to sanitize the restored database with",
+	)
+	refreshCmd.PersistentFlags().StringVarP(
+		&user,
+		"user",
+		"u",
+		"",
+		"User to connect to the restored database as",
+	)
+	refreshCmd.PersistentFlags().StringVarP(
+		&password,
+		"password",
+		"p",
+		"",
+		"Password to connect to the restored database with",
+	)
+	refreshCmd.PersistentFlags().StringVarP(
+		&database,
+		"database",
+		"d",
+		"postgres",
+		"Database to sanitize",
+	)
+	refreshCmd.PersistentFlags().StringVarP(
+		&instanceType,
+		"instance-type",
+		"t",
+		"db.m1.small",
+		"Instance type to use when restoring DB Instance",
+	)
+	refreshCmd.PersistentFlags().StringVarP(
+		&subnetName,
+		"subnet",
+		"n",
+		"",
+		"DB Subnet Name to attach to (effectively VPC)",
+	)
+	refreshCmd.PersistentFlags().StringVarP(
+		&securityGroups,
+		"securityGroups",
+		"g",
+		"",
+		"VPC SG IDs separated with , to attach to (effectively VPC)",
+	)
+	refreshCmd.PersistentFlags().StringVarP(
+		&finalSnapshotID,
+		"final-snapshot-id",
+		"",
+		"",
+		"Final snapshot ID of the replaced instance, if desired",
+	)
+}
@@ -9,6 +9,9 @@ const (
 	// snapshot create are not satisfied.
 	CreateParamsReq = `An existing available instance ID and
 a non existing snapshot ID are expected`
+	// SnapshotIDReq is the message to show when a snapshot is to be
+	// operated and no Id was specified.
+	SnapshotIDReq = "You must specify a snapshot identifier"
 )
 
 // SnapshotCmd represents the snapshot super command
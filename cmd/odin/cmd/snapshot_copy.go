@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/odin"
+)
+
+var sourceRegion, targetRegion, targetSnapshot, snapshotKMSKey string
+
+// snapshotCopyCmd represents the snapshot copy command
+var snapshotCopyCmd = &cobra.Command{
+	Use:   "copy [flags] snapshotID",
+	Short: "Copies a snapshot to another region",
+	Long: `Copies a snapshot to another region, waiting for the copy to
+be available. Encrypted snapshots need a KMS key of the destination
+region. E.g.:
+
+    odin snapshot copy rds:production-2018-06-11 --to-region eu-west-1`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			log.Fatal(SnapshotIDReq)
+		}
+		if targetRegion == "" {
+			log.Fatal("Error: You must specify a region to copy to")
+		}
+		snapshot, err := odin.GetSnapshot(args[0], rdsLogin(sourceRegion))
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		input, err := odin.CopySnapshotInput(
+			snapshot,
+			sourceRegion,
+			targetSnapshot,
+			snapshotKMSKey,
+		)
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		svc := rdsLogin(targetRegion)
+		output, err := svc.CopyDBSnapshot(input)
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		err = waitForSnapshot(output.DBSnapshot, svc, 30*time.Second)
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		fmt.Println(*output.DBSnapshot.DBSnapshotArn)
+	},
+}
+
+func init() {
+	SnapshotCmd.AddCommand(snapshotCopyCmd)
+
+	snapshotCopyCmd.PersistentFlags().StringVarP(
+		&sourceRegion,
+		"region",
+		"r",
+		"us-east-1",
+		"Region of the snapshot to copy",
+	)
+	snapshotCopyCmd.PersistentFlags().StringVarP(
+		&targetRegion,
+		"to-region",
+		"R",
+		"",
+		"Region to copy the snapshot to",
+	)
+	snapshotCopyCmd.PersistentFlags().StringVarP(
+		&targetSnapshot,
+		"target",
+		"t",
+		"",
+		"Identifier of the copy (default the snapshot's, without rds: prefix)",
+	)
+	snapshotCopyCmd.PersistentFlags().StringVarP(
+		&snapshotKMSKey,
+		"kms-key",
+		"k",
+		"",
+		"KMS key of the destination region to encrypt the copy with",
+	)
+}
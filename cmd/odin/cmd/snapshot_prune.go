@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/odin"
+)
+
+var pruneInstance string
+var retention odin.RetentionPolicy
+var dryRun bool
+
+// snapshotPruneCmd represents the snapshot prune command
+var snapshotPruneCmd = &cobra.Command{
+	Use:   "prune [flags]",
+	Short: "Deletes old manual snapshots",
+	Long: `Deletes the manual snapshots of an instance not kept by a
+grandfather-father-son retention policy. The newest snapshot of each of
+the latest days, weeks and months is kept. E.g.:
+
+    odin snapshot prune --instance production-rds --keep-daily 7 --keep-weekly 4 --keep-monthly 12 --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		svc := rdsLogin("us-east-1")
+		plan, err := odin.PlanPrune(pruneInstance, retention, svc)
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		deletions := 0
+		for _, p := range plan {
+			fmt.Println(p)
+			if !p.Keep {
+				deletions++
+			}
+		}
+		if dryRun {
+			fmt.Printf("%d snapshots would be deleted\n", deletions)
+			return
+		}
+		deleted, err := odin.Prune(plan, svc)
+		fmt.Printf("%d snapshots deleted\n", deleted)
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+	},
+}
+
+func init() {
+	SnapshotCmd.AddCommand(snapshotPruneCmd)
+
+	snapshotPruneCmd.PersistentFlags().StringVarP(
+		&pruneInstance,
+		"instance",
+		"i",
+		"",
+		"RDS Instance to prune snapshots of",
+	)
+	snapshotPruneCmd.PersistentFlags().IntVarP(
+		&retention.Daily,
+		"keep-daily",
+		"d",
+		7,
+		"Days to keep the newest snapshot of",
+	)
+	snapshotPruneCmd.PersistentFlags().IntVarP(
+		&retention.Weekly,
+		"keep-weekly",
+		"w",
+		4,
+		"Weeks to keep the newest snapshot of",
+	)
+	snapshotPruneCmd.PersistentFlags().IntVarP(
+		&retention.Monthly,
+		"keep-monthly",
+		"m",
+		12,
+		"Months to keep the newest snapshot of",
+	)
+	snapshotPruneCmd.PersistentFlags().BoolVarP(
+		&dryRun,
+		"dry-run",
+		"n",
+		false,
+		"Only show which snapshots would be deleted",
+	)
+}
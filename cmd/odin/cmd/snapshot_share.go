@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/odin"
+)
+
+var shareAccounts []string
+var shareRegion string
+var revoke bool
+
+// snapshotShareCmd represents the snapshot share command
+var snapshotShareCmd = &cobra.Command{
+	Use:   "share [flags] snapshotID",
+	Short: "Shares a snapshot with other accounts",
+	Long: `Allows other AWS accounts to restore a manual snapshot, or
+stops allowing them with --revoke. Snapshots copied to other regions
+are shared from there with --region. E.g.:
+
+    odin snapshot share production-2018-06-11 --account 123456789012
+    odin snapshot share production-2018-06-11 --region eu-west-1 --account 123456789012`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			log.Fatal(SnapshotIDReq)
+		}
+		svc := rdsLogin(shareRegion)
+		snapshot, err := odin.GetSnapshot(args[0], svc)
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		input, err := odin.ShareSnapshotInput(snapshot, shareAccounts, revoke)
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		output, err := svc.ModifyDBSnapshotAttribute(input)
+		if err != nil {
+			log.Fatalf("Error: %s", err)
+		}
+		if output.DBSnapshotAttributesResult == nil {
+			return
+		}
+		for _, attribute := range output.DBSnapshotAttributesResult.DBSnapshotAttributes {
+			if aws.StringValue(attribute.AttributeName) != "restore" {
+				continue
+			}
+			fmt.Printf(
+				"%s is shared with: %s\n",
+				args[0],
+				strings.Join(aws.StringValueSlice(attribute.AttributeValues), ", "),
+			)
+		}
+	},
+}
+
+func init() {
+	SnapshotCmd.AddCommand(snapshotShareCmd)
+
+	snapshotShareCmd.PersistentFlags().StringSliceVarP(
+		&shareAccounts,
+		"account",
+		"a",
+		[]string{},
+		"AWS account ID to share the snapshot with, or all to make it public",
+	)
+	snapshotShareCmd.PersistentFlags().StringVarP(
+		&shareRegion,
+		"region",
+		"r",
+		"us-east-1",
+		"Region of the snapshot to share",
+	)
+	snapshotShareCmd.PersistentFlags().BoolVarP(
+		&revoke,
+		"revoke",
+		"",
+		false,
+		"Stop sharing the snapshot with the accounts",
+	)
+}
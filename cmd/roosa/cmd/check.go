@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/roosa"
+)
+
+var maxDepth int
+
+// checkCmd represents the check command
+var checkCmd = &cobra.Command{
+	Use:   "check [flags] [file1] [[file2] [[...]]]",
+	Short: "Report CNAME loops and excessive CNAME chains",
+	Long: `
+This option reports loops of CNAMEs and aliases, and chains of
+them longer than --max-depth references, with the offending
+chain. It exits with status 1 if any is found, so it can be
+used as a CI check. Records are read from the JSON files given,
+in the format output by "aws route53 list-resource-record-sets"
+or as a list of record sets, or from the zones selected
+otherwise. The name filter applies to the first name of the
+chains reported. E.g.:
+
+    roosa check --zone example.com
+    roosa check --max-depth 2 zones/example.com.json
+    roosa check --format json zones/*.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var records []*route53.ResourceRecordSet
+		for _, path := range args {
+			file, err := os.Open(path)
+			if err != nil {
+				log.Fatal(err)
+			}
+			loaded, err := roosa.LoadRecords(file)
+			file.Close()
+			if err != nil {
+				log.Fatalf("Failed loading %s: %s", path, err)
+			}
+			records = append(records, loaded...)
+		}
+		if len(args) == 0 {
+			for _, zone := range loadZones(newSession()) {
+				records = append(records, zone.Records...)
+			}
+		}
+		var errs []*roosa.ChainError
+		for _, err := range roosa.NewReferenceTreeList(records).CheckChains(maxDepth) {
+			if filter().MatchName(err.Chain[0]) {
+				errs = append(errs, err)
+			}
+		}
+		switch outputFormat("text", "text", "json") {
+		case "text":
+			for _, err := range errs {
+				fmt.Println(err)
+			}
+		case "json":
+			printJSON(errs)
+		}
+		if len(errs) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(checkCmd)
+
+	checkCmd.Flags().IntVarP(
+		&maxDepth,
+		"max-depth",
+		"m",
+		roosa.DefaultMaxChainDepth,
+		"Maximum number of CNAMEs and aliases in a chain",
+	)
+}
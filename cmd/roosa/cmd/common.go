@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/route53"
+
+	"github.com/poka-yoke/spaceflight/pkg/roosa"
+)
+
+var zoneNames, filterTypes []string
+var format, filterName string
+var allZones, showResources bool
+
+// newSession returns an AWS session, exiting on failure.
+func newSession() *session.Session {
+	sess, err := session.NewSession()
+	if err != nil {
+		log.Fatalf("Failed to create session: %s", err)
+	}
+	return sess
+}
+
+// loadZones returns the zones selected in the command line, with all
+// their records.
+func loadZones(sess *session.Session) []roosa.Zone {
+	if len(zoneNames) == 0 && !allZones {
+		log.Fatal("Select zones with --zone or --all-zones")
+	}
+	if len(zoneNames) > 0 && allZones {
+		log.Fatal("--zone and --all-zones are mutually exclusive")
+	}
+	zones, err := roosa.GetZones(zoneNames, route53.New(sess))
+	if err != nil {
+		log.Fatal(err)
+	}
+	return zones
+}
+
+// filter returns the RecordFilter selected in the command line.
+func filter() roosa.RecordFilter {
+	return roosa.RecordFilter{
+		Types: filterTypes,
+		Name:  filterName,
+	}
+}
+
+// accountRegions returns selected, or all the regions available to the
+// account if none is. These are asked for in the region of the session,
+// or in the global one if it has none.
+func accountRegions(sess *session.Session, selected []string) []string {
+	if len(selected) > 0 {
+		return selected
+	}
+	config := aws.NewConfig()
+	if aws.StringValue(sess.Config.Region) == "" {
+		config = config.WithRegion(roosa.GlobalRegion)
+	}
+	regions, err := roosa.Regions(ec2.New(sess, config))
+	if err != nil {
+		log.Fatal(err)
+	}
+	return regions
+}
+
+// findResources returns the AWS resources in the account, in all the
+// regions, if requested in the command line.
+func findResources(sess *session.Session) roosa.Resources {
+	if !showResources {
+		return nil
+	}
+	resources, err := roosa.FindResources(
+		roosa.NewResourceFinders(sess, accountRegions(sess, nil)...)...,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return resources
+}
+
+// outputFormat returns the format selected in the command line, or
+// fallback, exiting if it's not one of allowed.
+func outputFormat(fallback string, allowed ...string) string {
+	if format == "" {
+		return fallback
+	}
+	for _, f := range allowed {
+		if f == format {
+			return format
+		}
+	}
+	log.Fatalf("Unknown format %s, use one of %v\n", format, allowed)
+	return ""
+}
+
+// printJSON prints v as indented JSON.
+func printJSON(v interface{}) {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(out))
+}
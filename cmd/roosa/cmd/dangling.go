@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/roosa"
+)
+
+var checkIPs bool
+var addressRegions []string
+
+// danglingCmd represents the dangling command
+var danglingCmd = &cobra.Command{
+	Use:   "dangling [flags]",
+	Short: "Report records at risk of subdomain takeover",
+	Long: `
+This option reports CNAMEs and aliases pointing to names which
+don't resolve, to missing records in the zones loaded, or to
+cloud endpoints reporting the resource doesn't exist, and, with
+--check-ips, A and AAAA records pointing to addresses not
+allocated in the account, in every region or those selected
+with --region. It exits with status 1 if any is
+found. The filters apply to the records reported. E.g.:
+
+    roosa dangling --zone example.com
+    roosa dangling --all-zones --check-ips --format json
+    roosa dangling --zone example.com --check-ips --region eu-west-1`,
+	Run: func(cmd *cobra.Command, args []string) {
+		sess := newSession()
+		zones := loadZones(sess)
+		var names []string
+		var records []*route53.ResourceRecordSet
+		for _, zone := range zones {
+			names = append(names, zone.Name)
+			records = append(records, zone.Records...)
+		}
+		checker := roosa.NewDanglingChecker(names...)
+		if checkIPs {
+			var clients []ec2iface.EC2API
+			for _, region := range accountRegions(sess, addressRegions) {
+				clients = append(
+					clients,
+					ec2.New(sess, aws.NewConfig().WithRegion(region)),
+				)
+			}
+			addresses, err := roosa.AccountAddresses(clients...)
+			if err != nil {
+				log.Fatal(err)
+			}
+			checker.Addresses = addresses
+		}
+		var findings []roosa.Finding
+		for _, f := range checker.Check(records) {
+			if filter().MatchName(f.Name) && filter().MatchType(f.Type) {
+				findings = append(findings, f)
+			}
+		}
+		switch outputFormat("text", "text", "json") {
+		case "text":
+			for _, f := range findings {
+				fmt.Println(f)
+			}
+		case "json":
+			printJSON(findings)
+		}
+		if len(findings) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(danglingCmd)
+
+	danglingCmd.Flags().BoolVarP(
+		&checkIPs,
+		"check-ips",
+		"",
+		false,
+		"Report A records pointing to addresses not allocated in the account",
+	)
+	danglingCmd.Flags().StringSliceVarP(
+		&addressRegions,
+		"region",
+		"",
+		nil,
+		"Regions to look for addresses in with --check-ips, all by default (repeatable)",
+	)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/roosa"
+)
+
+// graphCmd represents the graph command
+var graphCmd = &cobra.Command{
+	Use:   "graph [flags]",
+	Short: "Show the dependency graph of the records",
+	Long: `
+This option outputs the dependency graph of the records, in
+DOT (default), Mermaid or JSON format: CNAME, MX, SRV and NS
+targets, alias targets and the addresses of A and AAAA
+records, for every value of each record. Each zone is drawn
+as a cluster, with cross zone edges bold. The filters apply
+before building the graph. E.g.:
+
+    roosa graph --zone example.com | dot -Tpng > example.png
+    roosa graph --all-zones --format mermaid
+    roosa graph --zone example.com --type CNAME --resources --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		sess := newSession()
+		g := roosa.NewZoneGraph(filter().Apply(loadZones(sess)))
+		g.SetResources(findResources(sess))
+		switch outputFormat("dot", "dot", "mermaid", "json") {
+		case "dot":
+			fmt.Print(g.DOT())
+		case "mermaid":
+			fmt.Print(g.Mermaid())
+		case "json":
+			out, err := g.JSON()
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Print(out)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(graphCmd)
+
+	graphCmd.Flags().BoolVarP(
+		&showResources,
+		"resources",
+		"r",
+		false,
+		"Show the AWS resources, in any region, behind each address and name",
+	)
+}
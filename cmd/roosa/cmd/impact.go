@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/roosa"
+)
+
+// impactCmd represents the impact command
+var impactCmd = &cobra.Command{
+	Use:   "impact [flags] <name|ip>",
+	Short: "Show the names affected by changes to a record or IP",
+	Long: `
+This option lists every name which would be affected if the
+given record or address changed, following CNAME chains,
+aliases, and MX, SRV and NS records, along with the chain of
+references leading to it. The name filter applies to the
+names listed. E.g.:
+
+    roosa impact --zone example.com origin.example.com
+    roosa impact --all-zones --format json 10.0.0.1`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			log.Fatal("A name or IP to analyze is expected")
+		}
+		g := roosa.NewZoneGraph(loadZones(newSession()))
+		impacts, err := g.Impact(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		var filtered []roosa.Impact
+		for _, i := range impacts {
+			if filter().MatchName(i.Name) {
+				filtered = append(filtered, i)
+			}
+		}
+		switch outputFormat("text", "text", "json") {
+		case "text":
+			for _, i := range filtered {
+				fmt.Println(i)
+			}
+		case "json":
+			printJSON(filtered)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(impactCmd)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// RootCmd represents the base command when called without any subcommands
+var RootCmd = &cobra.Command{
+	Use:   "roosa",
+	Short: "A tool to detect and visualize relationships among DNS records",
+	Long: `roosa detects and visualizes the relationships among DNS records.
+Currently, it is usable for AWS Route53 DNS records only.`,
+}
+
+// Execute adds all child commands to the root command sets flags appropriately.
+// This is called by main.main(). It only needs to happen once to the rootCmd.
+func Execute() {
+	if err := RootCmd.Execute(); err != nil {
+		fmt.Println(err)
+		os.Exit(-1)
+	}
+}
+
+func init() {
+	RootCmd.PersistentFlags().StringSliceVarP(
+		&zoneNames,
+		"zone",
+		"z",
+		nil,
+		"Hosted Zones to load (repeatable)",
+	)
+	RootCmd.PersistentFlags().BoolVarP(
+		&allZones,
+		"all-zones",
+		"a",
+		false,
+		"Load all Hosted Zones in the account",
+	)
+	RootCmd.PersistentFlags().StringVarP(
+		&format,
+		"format",
+		"f",
+		"",
+		"Output format: text, json, dot or mermaid, depending on the command",
+	)
+	RootCmd.PersistentFlags().StringSliceVarP(
+		&filterTypes,
+		"type",
+		"t",
+		nil,
+		"Only show records of this type (repeatable)",
+	)
+	RootCmd.PersistentFlags().StringVarP(
+		&filterName,
+		"name",
+		"n",
+		"",
+		"Only show records whose name matches this pattern, like '*.example.com'",
+	)
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/roosa"
+)
+
+// treeCmd represents the tree command
+var treeCmd = &cobra.Command{
+	Use:   "tree [flags]",
+	Short: "Show the reference trees of the records",
+	Long: `
+This option prints the reference trees of A, AAAA and CNAME
+records, aliases included, with the records pointing to each
+one indented below it. Records referencing another of the
+zones loaded show their own zone, and with --resources every
+record shows the AWS resources behind its values. The filters
+apply before building the trees. E.g.:
+
+    roosa tree --zone example.com
+    roosa tree --zone example.com --zone example.org --resources
+    roosa tree --all-zones --name '*.example.com' --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		sess := newSession()
+		zones := filter().Apply(loadZones(sess))
+		rtl := roosa.NewZoneReferenceTreeList(zones)
+		rtl.SetResources(findResources(sess))
+		switch outputFormat("text", "text", "json") {
+		case "text":
+			fmt.Print(rtl)
+		case "json":
+			out, err := rtl.JSON()
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Print(out)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(treeCmd)
+
+	treeCmd.Flags().BoolVarP(
+		&showResources,
+		"resources",
+		"r",
+		false,
+		"Show the AWS resources, in any region, behind each record",
+	)
+}
@@ -0,0 +1,12 @@
+package diagram
+
+import "encoding/json"
+
+// JSON returns graph as an indented JSON document.
+func JSON(graph interface{}) (string, error) {
+	out, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out) + "\n", nil
+}
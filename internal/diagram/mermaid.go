@@ -0,0 +1,86 @@
+// Package diagram renders the graphs of the tools as Mermaid flowcharts
+// and JSON documents.
+package diagram
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Shapes of the nodes in a Mermaid flowchart.
+const (
+	Box     = "%s[\"%s\"]"
+	Rounded = "%s(\"%s\")"
+	Circle  = "%s((\"%s\"))"
+)
+
+// Arrows of the edges in a Mermaid flowchart.
+const (
+	Arrow  = "-->"
+	Thick  = "==>"
+	Dotted = "-.->"
+)
+
+var mermaidInvalidID = regexp.MustCompile("[^a-zA-Z0-9_]")
+
+// ID returns the Mermaid node id for id.
+func ID(id string) string {
+	return "n_" + mermaidInvalidID.ReplaceAllString(id, "_")
+}
+
+// Mermaid writes a left to right Mermaid flowchart.
+type Mermaid struct {
+	b     strings.Builder
+	depth int
+}
+
+// NewMermaid returns an empty flowchart.
+func NewMermaid() *Mermaid {
+	m := &Mermaid{}
+	m.b.WriteString("graph LR\n")
+	return m
+}
+
+func (m *Mermaid) line(format string, a ...interface{}) {
+	m.b.WriteString(strings.Repeat("  ", m.depth+1))
+	fmt.Fprintf(&m.b, format, a...)
+	m.b.WriteString("\n")
+}
+
+// Subgraph opens a subgraph titled title, which holds the nodes added
+// until End. Its id is kept apart from the ones of nodes.
+func (m *Mermaid) Subgraph(title string) {
+	m.line(
+		"subgraph z_%s[\"%s\"]",
+		mermaidInvalidID.ReplaceAllString(title, "_"),
+		title,
+	)
+	m.depth++
+}
+
+// End closes the last subgraph opened.
+func (m *Mermaid) End() {
+	m.depth--
+	m.line("end")
+}
+
+// Node adds the node id, drawn with shape and label.
+func (m *Mermaid) Node(id string, label string, shape string) {
+	m.line(shape, ID(id), label)
+}
+
+// Edge adds an edge from a node to another, drawn with arrow and label.
+func (m *Mermaid) Edge(from string, to string, label string, arrow string) {
+	m.line("%s %s|\"%s\"| %s", ID(from), arrow, label, ID(to))
+}
+
+// Style sets the style of the node id.
+func (m *Mermaid) Style(id string, style string) {
+	m.line("style %s %s", ID(id), style)
+}
+
+// String returns the flowchart written so far.
+func (m *Mermaid) String() string {
+	return m.b.String()
+}
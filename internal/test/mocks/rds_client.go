@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/rds"
 	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
 )
@@ -23,6 +24,7 @@ type RDSClient struct {
 	rdsiface.RDSAPI
 	dbInstances []*rds.DBInstance
 	dbSnapshots []*rds.DBSnapshot
+	tags        map[string][]*rds.Tag
 }
 
 // TakeFinalSnapshot emulates taking the final snapshot, if specified,
@@ -146,16 +148,17 @@ func (m RDSClient) findInstance(id string) (
 		}
 	}
 	if !found {
-		err = fmt.Errorf(
-			"No such instance %s",
-			id,
+		err = awserr.New(
+			rds.ErrCodeDBInstanceNotFoundFault,
+			fmt.Sprintf("No such instance %s", id),
+			nil,
 		)
 	}
 	return
 }
 
-// addInstances add a list of instances to the mock
-func (m *RDSClient) addInstances(
+// AddInstances add a list of instances to the mock
+func (m *RDSClient) AddInstances(
 	instances []*rds.DBInstance,
 ) {
 	m.dbInstances = []*rds.DBInstance{}
@@ -213,6 +216,31 @@ func (m *RDSClient) CreateDBSnapshot(
 	return
 }
 
+// DeleteDBSnapshot mocks rds.DeleteDBSnapshot.
+func (m *RDSClient) DeleteDBSnapshot(
+	params *rds.DeleteDBSnapshotInput,
+) (
+	output *rds.DeleteDBSnapshotOutput,
+	err error,
+) {
+	if err = params.Validate(); err != nil {
+		return
+	}
+	index, snapshot, err := m.FindSnapshot(*params.DBSnapshotIdentifier)
+	if err != nil {
+		return
+	}
+	m.dbSnapshots = append(
+		m.dbSnapshots[:index],
+		m.dbSnapshots[index+1:]...,
+	)
+	snapshot.Status = aws.String("deleted")
+	output = &rds.DeleteDBSnapshotOutput{
+		DBSnapshot: snapshot,
+	}
+	return
+}
+
 // DescribeDBSnapshots mocks rds.DescribeDBSnapshots.
 func (m RDSClient) DescribeDBSnapshots(
 	describeParams *rds.DescribeDBSnapshotsInput,
@@ -221,7 +249,14 @@ func (m RDSClient) DescribeDBSnapshots(
 	err error,
 ) {
 	var snapshots []*rds.DBSnapshot
-	if describeParams.DBInstanceIdentifier != nil {
+	if describeParams.DBSnapshotIdentifier != nil {
+		var snapshot *rds.DBSnapshot
+		_, snapshot, err = m.FindSnapshot(*describeParams.DBSnapshotIdentifier)
+		if err != nil {
+			return
+		}
+		snapshots = []*rds.DBSnapshot{snapshot}
+	} else if describeParams.DBInstanceIdentifier != nil {
 		snapshots = []*rds.DBSnapshot{}
 		id := describeParams.DBInstanceIdentifier
 		for _, snapshot := range m.dbSnapshots {
@@ -235,12 +270,35 @@ func (m RDSClient) DescribeDBSnapshots(
 	} else {
 		snapshots = m.dbSnapshots
 	}
+	if describeParams.SnapshotType != nil {
+		var filtered []*rds.DBSnapshot
+		for _, snapshot := range snapshots {
+			if aws.StringValue(snapshot.SnapshotType) == *describeParams.SnapshotType {
+				filtered = append(filtered, snapshot)
+			}
+		}
+		snapshots = filtered
+	}
 	result = &rds.DescribeDBSnapshotsOutput{
 		DBSnapshots: snapshots,
 	}
 	return
 }
 
+// DescribeDBSnapshotsPages mocks rds.DescribeDBSnapshotsPages, returning
+// all snapshots in a single page.
+func (m RDSClient) DescribeDBSnapshotsPages(
+	describeParams *rds.DescribeDBSnapshotsInput,
+	fn func(*rds.DescribeDBSnapshotsOutput, bool) bool,
+) error {
+	result, err := m.DescribeDBSnapshots(describeParams)
+	if err != nil {
+		return err
+	}
+	fn(result, true)
+	return nil
+}
+
 // DescribeDBInstances mocks rds.DescribeDBInstances.
 func (m *RDSClient) DescribeDBInstances(
 	describeParams *rds.DescribeDBInstancesInput,
@@ -249,6 +307,12 @@ func (m *RDSClient) DescribeDBInstances(
 	err error,
 ) {
 	id := describeParams.DBInstanceIdentifier
+	if id == nil {
+		result = &rds.DescribeDBInstancesOutput{
+			DBInstances: m.dbInstances,
+		}
+		return
+	}
 	index, instance, err := m.findInstance(*id)
 	if err != nil {
 		return
@@ -286,6 +350,27 @@ func (m *RDSClient) DescribeDBInstances(
 	return
 }
 
+// AddTags sets the tags of the resource with the arn.
+func (m *RDSClient) AddTags(arn string, tags []*rds.Tag) {
+	m.tags[arn] = tags
+}
+
+// ListTagsForResource mocks rds.ListTagsForResource.
+func (m *RDSClient) ListTagsForResource(
+	params *rds.ListTagsForResourceInput,
+) (
+	out *rds.ListTagsForResourceOutput,
+	err error,
+) {
+	if err = params.Validate(); err != nil {
+		return
+	}
+	out = &rds.ListTagsForResourceOutput{
+		TagList: m.tags[*params.ResourceName],
+	}
+	return
+}
+
 // CreateDBInstance mocks rds.CreateDBInstance.
 func (m *RDSClient) CreateDBInstance(
 	inputParams *rds.CreateDBInstanceInput,
@@ -408,6 +493,16 @@ func (m *RDSClient) ModifyDBInstance(
 			params.DBInstanceClass != instance.DBInstanceClass {
 			instance.DBInstanceClass = params.DBInstanceClass
 		}
+		if params.NewDBInstanceIdentifier != nil {
+			if _, _, e := m.findInstance(*params.NewDBInstanceIdentifier); e == nil {
+				err = fmt.Errorf(
+					"%s instance already exists",
+					*params.NewDBInstanceIdentifier,
+				)
+				return
+			}
+			instance.DBInstanceIdentifier = params.NewDBInstanceIdentifier
+		}
 	}
 	m.dbInstances[index] = instance
 	out = &rds.ModifyDBInstanceOutput{
@@ -421,5 +516,6 @@ func NewRDSClient() *RDSClient {
 	return &RDSClient{
 		dbInstances: []*rds.DBInstance{},
 		dbSnapshots: []*rds.DBSnapshot{},
+		tags:        map[string][]*rds.Tag{},
 	}
 }
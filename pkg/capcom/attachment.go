@@ -0,0 +1,210 @@
+package capcom
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+)
+
+// Kinds of resources a network interface can belong to.
+const (
+	AttachmentInstance     = "instance"
+	AttachmentRDS          = "rds"
+	AttachmentLoadBalancer = "load-balancer"
+	AttachmentLambda       = "lambda"
+	AttachmentNatGateway   = "nat-gateway"
+	AttachmentOther        = "other"
+)
+
+// Attachment is a network interface using a Security Group, and the
+// resource it belongs to.
+type Attachment struct {
+	NetworkInterfaceID string   `json:"network_interface_id"`
+	Kind               string   `json:"kind"`
+	ResourceID         string   `json:"resource_id,omitempty"`
+	PrivateIPs         []string `json:"private_ips"`
+	PublicIPs          []string `json:"public_ips,omitempty"`
+}
+
+// String method for Attachment gets a String to be printed.
+func (a Attachment) String() string {
+	out := fmt.Sprintf(
+		"%s %s %s %s",
+		a.NetworkInterfaceID,
+		a.Kind,
+		a.ResourceID,
+		strings.Join(a.PrivateIPs, ","),
+	)
+	if len(a.PublicIPs) > 0 {
+		out = fmt.Sprintf("%s %s", out, strings.Join(a.PublicIPs, ","))
+	}
+	return out
+}
+
+// describeGroupInterfaces retrieves the network interfaces attached to
+// sgid.
+func describeGroupInterfaces(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+	sgid string,
+) ([]*ec2.NetworkInterface, error) {
+	res, err := svc.DescribeNetworkInterfacesWithContext(
+		ctx,
+		&ec2.DescribeNetworkInterfacesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("group-id"),
+					Values: []*string{aws.String(sgid)},
+				},
+			},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return res.NetworkInterfaces, nil
+}
+
+// FindAttachments returns the network interfaces using sgid, and the
+// instances, RDS instances, load balancers or Lambda functions they
+// belong to. RDS network interfaces are resolved into their DB instance
+// through rdssvc, if not nil, when a single one in their VPC uses sgid.
+func FindAttachments(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+	rdssvc rdsiface.RDSAPI,
+	sgid string,
+) (out []Attachment, err error) {
+	enis, err := describeGroupInterfaces(ctx, svc, sgid)
+	if err != nil {
+		return
+	}
+	var databases map[string][]string
+	for _, eni := range enis {
+		a := newAttachment(eni)
+		if a.Kind == AttachmentRDS && rdssvc != nil {
+			if databases == nil {
+				databases, err = groupDBInstances(ctx, rdssvc, sgid)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if ids := databases[aws.StringValue(eni.VpcId)]; len(ids) == 1 {
+				a.ResourceID = ids[0]
+			}
+		}
+		out = append(out, a)
+	}
+	return
+}
+
+// groupDBInstances returns the identifiers of the DB instances using
+// sgid, by VPC.
+func groupDBInstances(
+	ctx aws.Context,
+	svc rdsiface.RDSAPI,
+	sgid string,
+) (map[string][]string, error) {
+	out := make(map[string][]string)
+	err := svc.DescribeDBInstancesPagesWithContext(
+		ctx,
+		&rds.DescribeDBInstancesInput{},
+		func(page *rds.DescribeDBInstancesOutput, lastPage bool) bool {
+			for _, db := range page.DBInstances {
+				if db.DBSubnetGroup == nil {
+					continue
+				}
+				for _, group := range db.VpcSecurityGroups {
+					if aws.StringValue(group.VpcSecurityGroupId) == sgid {
+						vpcid := aws.StringValue(db.DBSubnetGroup.VpcId)
+						out[vpcid] = append(
+							out[vpcid],
+							aws.StringValue(db.DBInstanceIdentifier),
+						)
+						break
+					}
+				}
+			}
+			return true
+		},
+	)
+	return out, err
+}
+
+// newAttachment classifies a network interface after its attachment,
+// type and the description AWS services give to the ones they manage.
+func newAttachment(eni *ec2.NetworkInterface) (a Attachment) {
+	a.NetworkInterfaceID = aws.StringValue(eni.NetworkInterfaceId)
+	for _, address := range eni.PrivateIpAddresses {
+		a.PrivateIPs = append(
+			a.PrivateIPs,
+			aws.StringValue(address.PrivateIpAddress),
+		)
+		if address.Association != nil && address.Association.PublicIp != nil {
+			a.PublicIPs = append(a.PublicIPs, *address.Association.PublicIp)
+		}
+	}
+	if len(a.PrivateIPs) == 0 && eni.PrivateIpAddress != nil {
+		a.PrivateIPs = []string{*eni.PrivateIpAddress}
+	}
+	if len(a.PublicIPs) == 0 &&
+		eni.Association != nil &&
+		eni.Association.PublicIp != nil {
+		a.PublicIPs = []string{*eni.Association.PublicIp}
+	}
+
+	description := aws.StringValue(eni.Description)
+	switch {
+	case eni.Attachment != nil && eni.Attachment.InstanceId != nil:
+		a.Kind = AttachmentInstance
+		a.ResourceID = *eni.Attachment.InstanceId
+	case aws.StringValue(eni.InterfaceType) == ec2.NetworkInterfaceTypeNatGateway:
+		a.Kind = AttachmentNatGateway
+		a.ResourceID = strings.TrimPrefix(description, "Interface for NAT Gateway ")
+	case description == "RDSNetworkInterface":
+		// AWS doesn't tell which DB instance the interface belongs
+		// to, so it stands for it unless FindAttachments finds out.
+		a.Kind = AttachmentRDS
+		a.ResourceID = a.NetworkInterfaceID
+	case strings.HasPrefix(description, "ELB "):
+		a.Kind = AttachmentLoadBalancer
+		a.ResourceID = strings.TrimPrefix(description, "ELB ")
+	case strings.HasPrefix(description, "AWS Lambda VPC ENI"):
+		a.Kind = AttachmentLambda
+		if i := strings.Index(description, ": "); i >= 0 {
+			a.ResourceID = description[i+2:]
+		}
+	default:
+		a.Kind = AttachmentOther
+		a.ResourceID = description
+	}
+	return
+}
+
+// AddAttachments fills in the network interfaces using the group of
+// each SearchResult, so it is known what a rule exposes.
+func AddAttachments(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+	rdssvc rdsiface.RDSAPI,
+	results []SearchResult,
+) error {
+	cache := make(map[string][]Attachment)
+	for i := range results {
+		sgid := results[i].GroupID
+		if _, ok := cache[sgid]; !ok {
+			attachments, err := FindAttachments(ctx, svc, rdssvc, sgid)
+			if err != nil {
+				return err
+			}
+			cache[sgid] = attachments
+		}
+		results[i].Attachments = cache[sgid]
+	}
+	return nil
+}
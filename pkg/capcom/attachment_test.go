@@ -0,0 +1,186 @@
+package capcom
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+)
+
+func TestNewAttachment(t *testing.T) {
+	data := []struct {
+		eni      *ec2.NetworkInterface
+		kind     string
+		resource string
+	}{
+		{
+			eni: &ec2.NetworkInterface{
+				NetworkInterfaceId: aws.String("eni-1"),
+				Attachment: &ec2.NetworkInterfaceAttachment{
+					InstanceId: aws.String("i-1234"),
+				},
+			},
+			kind:     AttachmentInstance,
+			resource: "i-1234",
+		},
+		{
+			eni: &ec2.NetworkInterface{
+				NetworkInterfaceId: aws.String("eni-2"),
+				Description:        aws.String("RDSNetworkInterface"),
+			},
+			kind:     AttachmentRDS,
+			resource: "eni-2",
+		},
+		{
+			eni: &ec2.NetworkInterface{
+				NetworkInterfaceId: aws.String("eni-3"),
+				Description:        aws.String("ELB app/web/1234"),
+			},
+			kind:     AttachmentLoadBalancer,
+			resource: "app/web/1234",
+		},
+		{
+			eni: &ec2.NetworkInterface{
+				NetworkInterfaceId: aws.String("eni-4"),
+				Description:        aws.String("AWS Lambda VPC ENI: 1234-abcd"),
+			},
+			kind:     AttachmentLambda,
+			resource: "1234-abcd",
+		},
+		{
+			eni: &ec2.NetworkInterface{
+				NetworkInterfaceId: aws.String("eni-5"),
+				Description:        aws.String("Interface for NAT Gateway nat-1234"),
+				InterfaceType:      aws.String("natGateway"),
+			},
+			kind:     AttachmentNatGateway,
+			resource: "nat-1234",
+		},
+		{
+			eni: &ec2.NetworkInterface{
+				NetworkInterfaceId: aws.String("eni-6"),
+				Description:        aws.String("Something else"),
+			},
+			kind:     AttachmentOther,
+			resource: "Something else",
+		},
+	}
+	for _, tc := range data {
+		a := newAttachment(tc.eni)
+		if a.Kind != tc.kind || a.ResourceID != tc.resource {
+			t.Errorf("Unexpected attachment %v", a)
+		}
+	}
+}
+
+func TestNewAttachmentAddresses(t *testing.T) {
+	a := newAttachment(&ec2.NetworkInterface{
+		NetworkInterfaceId: aws.String("eni-1"),
+		PrivateIpAddresses: []*ec2.NetworkInterfacePrivateIpAddress{
+			{
+				PrivateIpAddress: aws.String("10.0.0.1"),
+				Association: &ec2.NetworkInterfaceAssociation{
+					PublicIp: aws.String("54.0.0.1"),
+				},
+			},
+			{PrivateIpAddress: aws.String("10.0.0.2")},
+		},
+	})
+	expected := "eni-1 other  10.0.0.1,10.0.0.2 54.0.0.1"
+	if a.String() != expected {
+		t.Errorf("%s is not %s", a, expected)
+	}
+}
+
+func TestAddAttachments(t *testing.T) {
+	svc := &mockEC2Client{}
+	results := []SearchResult{{GroupID: "sg-1234"}, {GroupID: "sg-1234"}}
+	if err := AddAttachments(aws.BackgroundContext(), svc, nil, results); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if len(r.Attachments) != 1 ||
+			r.Attachments[0].NetworkInterfaceID != "eni-1234" {
+			t.Errorf("Unexpected attachments %v", r.Attachments)
+		}
+	}
+}
+
+type rdsEC2Client struct {
+	mockEC2Client
+}
+
+func (m *rdsEC2Client) DescribeNetworkInterfacesWithContext(
+	ctx aws.Context,
+	in *ec2.DescribeNetworkInterfacesInput,
+	opts ...request.Option,
+) (
+	*ec2.DescribeNetworkInterfacesOutput,
+	error,
+) {
+	return &ec2.DescribeNetworkInterfacesOutput{
+		NetworkInterfaces: []*ec2.NetworkInterface{
+			{
+				NetworkInterfaceId: aws.String("eni-1"),
+				Description:        aws.String("RDSNetworkInterface"),
+				VpcId:              aws.String("vpc-1"),
+			},
+			{
+				NetworkInterfaceId: aws.String("eni-2"),
+				Description:        aws.String("RDSNetworkInterface"),
+				VpcId:              aws.String("vpc-2"),
+			},
+		},
+	}, nil
+}
+
+type mockRDSClient struct {
+	rdsiface.RDSAPI
+}
+
+func (m *mockRDSClient) DescribeDBInstancesPagesWithContext(
+	ctx aws.Context,
+	in *rds.DescribeDBInstancesInput,
+	fn func(*rds.DescribeDBInstancesOutput, bool) bool,
+	opts ...request.Option,
+) error {
+	instance := func(id string, vpcid string, sgid string) *rds.DBInstance {
+		return &rds.DBInstance{
+			DBInstanceIdentifier: aws.String(id),
+			DBSubnetGroup:        &rds.DBSubnetGroup{VpcId: aws.String(vpcid)},
+			VpcSecurityGroups: []*rds.VpcSecurityGroupMembership{
+				{VpcSecurityGroupId: aws.String(sgid)},
+			},
+		}
+	}
+	fn(&rds.DescribeDBInstancesOutput{
+		DBInstances: []*rds.DBInstance{
+			instance("production", "vpc-1", "sg-1234"),
+			instance("other", "vpc-1", "sg-5678"),
+			instance("staging", "vpc-2", "sg-1234"),
+			instance("testing", "vpc-2", "sg-1234"),
+		},
+	}, true)
+	return nil
+}
+
+func TestFindAttachmentsRDS(t *testing.T) {
+	out, err := FindAttachments(
+		aws.BackgroundContext(),
+		&rdsEC2Client{},
+		&mockRDSClient{},
+		"sg-1234",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The DB instance is only known when a single one is in the VPC.
+	if len(out) != 2 ||
+		out[0].ResourceID != "production" ||
+		out[1].ResourceID != "eni-2" {
+		t.Errorf("Unexpected attachments %v", out)
+	}
+}
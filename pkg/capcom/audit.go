@@ -0,0 +1,301 @@
+package capcom
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// Severity classifies how risky a Finding is.
+type Severity string
+
+// Severities for audit findings, from least to most risky.
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Names of the checks performed by Audit.
+const (
+	CheckWorldOpen          = "world-open"
+	CheckUnused             = "unused"
+	CheckDanglingReference  = "dangling-reference"
+	CheckDuplicateRule      = "duplicate-rule"
+	CheckShadowedRule       = "shadowed-rule"
+	CheckMissingDescription = "missing-description"
+	CheckBroadPortRange     = "broad-port-range"
+)
+
+// DefaultSensitivePorts lists the ports which should never be open to
+// the whole Internet.
+var DefaultSensitivePorts = []int64{
+	22,    // SSH
+	23,    // Telnet
+	445,   // SMB
+	1433,  // MS SQL Server
+	2375,  // Docker
+	3306,  // MySQL
+	3389,  // RDP
+	5432,  // PostgreSQL
+	5984,  // CouchDB
+	6379,  // Redis
+	9200,  // Elasticsearch
+	11211, // Memcached
+	27017, // MongoDB
+}
+
+// DefaultMaxPortRange is the widest port range a rule can span before
+// being reported as overly broad.
+const DefaultMaxPortRange = 1024
+
+// AuditOptions tunes the checks performed by Audit.
+type AuditOptions struct {
+	SensitivePorts []int64
+	MaxPortRange   int64
+}
+
+// Finding describes a risky condition detected in a Security Group.
+type Finding struct {
+	Location
+	GroupID   string   `json:"group_id"`
+	GroupName string   `json:"group_name"`
+	Check     string   `json:"check"`
+	Severity  Severity `json:"severity"`
+	Message   string   `json:"message"`
+}
+
+// String method for Finding gets a String to be printed.
+func (f Finding) String() string {
+	out := fmt.Sprintf(
+		"[%s] %s (%s) %s: %s",
+		f.Severity,
+		f.GroupID,
+		f.GroupName,
+		f.Check,
+		f.Message,
+	)
+	if location := f.Location.String(); location != "" {
+		out = fmt.Sprintf("[%s] %s", location, out)
+	}
+	return out
+}
+
+// Audit inspects all Security Groups in the account and returns the
+// findings on risky or untidy rules.
+func Audit(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+	opts AuditOptions,
+) ([]Finding, error) {
+	sglist, err := getSecurityGroups(ctx, svc)
+	if err != nil {
+		return nil, err
+	}
+	used, err := getUsedSecurityGroups(ctx, svc)
+	if err != nil {
+		return nil, err
+	}
+	return auditSecurityGroups(sglist.SecurityGroups, used, opts), nil
+}
+
+// getUsedSecurityGroups returns the set of sgids attached to, at
+// least, one network interface.
+func getUsedSecurityGroups(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+) (map[string]bool, error) {
+	res, err := svc.DescribeNetworkInterfacesWithContext(
+		ctx,
+		&ec2.DescribeNetworkInterfacesInput{},
+	)
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[string]bool)
+	for _, eni := range res.NetworkInterfaces {
+		for _, group := range eni.Groups {
+			used[aws.StringValue(group.GroupId)] = true
+		}
+	}
+	return used, nil
+}
+
+func auditSecurityGroups(
+	sglist []*ec2.SecurityGroup,
+	used map[string]bool,
+	opts AuditOptions,
+) (out []Finding) {
+	if opts.SensitivePorts == nil {
+		opts.SensitivePorts = DefaultSensitivePorts
+	}
+	if opts.MaxPortRange == 0 {
+		opts.MaxPortRange = DefaultMaxPortRange
+	}
+	known := make(map[string]bool)
+	for _, sg := range sglist {
+		known[aws.StringValue(sg.GroupId)] = true
+	}
+	for _, sg := range sglist {
+		finding := func(check string, severity Severity, format string, v ...interface{}) {
+			out = append(out, Finding{
+				GroupID:   aws.StringValue(sg.GroupId),
+				GroupName: aws.StringValue(sg.GroupName),
+				Check:     check,
+				Severity:  severity,
+				Message:   fmt.Sprintf(format, v...),
+			})
+		}
+		if isUnused(sg, used) {
+			finding(
+				CheckUnused,
+				SeverityInfo,
+				"not attached to any network interface",
+			)
+		}
+		for _, sgid := range danglingReferences(sg, known) {
+			finding(
+				CheckDanglingReference,
+				SeverityWarning,
+				"references missing group %s",
+				sgid,
+			)
+		}
+		rules := Rules(sg)
+		for i, rule := range rules {
+			if rule.Egress {
+				continue
+			}
+			if ports := worldOpenPorts(rule, opts.SensitivePorts); len(ports) > 0 {
+				finding(
+					CheckWorldOpen,
+					SeverityCritical,
+					"%s exposes sensitive ports %s",
+					rule,
+					joinPorts(ports),
+				)
+			}
+			if rule.PortSpan() > opts.MaxPortRange {
+				finding(
+					CheckBroadPortRange,
+					SeverityWarning,
+					"%s spans %d ports",
+					rule,
+					rule.PortSpan(),
+				)
+			}
+			if rule.Description == "" {
+				finding(
+					CheckMissingDescription,
+					SeverityInfo,
+					"%s has no description",
+					rule,
+				)
+			}
+			for j, other := range rules {
+				if i == j || !other.coversRule(rule) ||
+					!peerContains(other.Peer, rule.Peer) {
+					continue
+				}
+				if rule.coversRule(other) && peerContains(rule.Peer, other.Peer) {
+					// Report each pair of duplicates only once
+					if j > i {
+						finding(
+							CheckDuplicateRule,
+							SeverityInfo,
+							"%s is duplicated",
+							rule,
+						)
+					}
+					continue
+				}
+				finding(
+					CheckShadowedRule,
+					SeverityInfo,
+					"%s is shadowed by %s",
+					rule,
+					other,
+				)
+				break
+			}
+		}
+	}
+	return
+}
+
+// isUnused returns true if sg isn't attached to any network interface.
+// Default groups can't be deleted, so they are never reported.
+func isUnused(sg *ec2.SecurityGroup, used map[string]bool) bool {
+	return !used[aws.StringValue(sg.GroupId)] &&
+		aws.StringValue(sg.GroupName) != "default"
+}
+
+// danglingReferences returns the sgids referenced by sg rules which
+// don't exist. References to other accounts or through VPC peerings
+// can't be verified and are ignored.
+func danglingReferences(sg *ec2.SecurityGroup, known map[string]bool) (out []string) {
+	seen := make(map[string]bool)
+	perms := append([]*ec2.IpPermission{}, sg.IpPermissions...)
+	perms = append(perms, sg.IpPermissionsEgress...)
+	for _, perm := range perms {
+		for _, pair := range perm.UserIdGroupPairs {
+			sgid := aws.StringValue(pair.GroupId)
+			if pair.VpcPeeringConnectionId != nil ||
+				(pair.UserId != nil && sg.OwnerId != nil &&
+					*pair.UserId != *sg.OwnerId) {
+				continue
+			}
+			if !known[sgid] && !seen[sgid] {
+				seen[sgid] = true
+				out = append(out, sgid)
+			}
+		}
+	}
+	return
+}
+
+// worldOpenPorts returns the sensitive ports rule opens to any address.
+func worldOpenPorts(rule Rule, sensitive []int64) (out []int64) {
+	if rule.Peer != "0.0.0.0/0" && rule.Peer != "::/0" {
+		return
+	}
+	for _, port := range sensitive {
+		if rule.CoversPort("tcp", port) || rule.CoversPort("udp", port) {
+			out = append(out, port)
+		}
+	}
+	return
+}
+
+// peerContains returns true if every address matched by peer inner is
+// matched by peer outer as well.
+func peerContains(outer, inner string) bool {
+	if outer == inner {
+		return true
+	}
+	_, outerNet, err := net.ParseCIDR(outer)
+	if err != nil {
+		return false
+	}
+	innerIP, innerNet, err := net.ParseCIDR(inner)
+	if err != nil {
+		return false
+	}
+	outerOnes, outerBits := outerNet.Mask.Size()
+	innerOnes, innerBits := innerNet.Mask.Size()
+	return outerBits == innerBits &&
+		outerOnes <= innerOnes &&
+		outerNet.Contains(innerIP)
+}
+
+func joinPorts(ports []int64) string {
+	out := make([]string, len(ports))
+	for i, port := range ports {
+		out[i] = fmt.Sprintf("%d", port)
+	}
+	return strings.Join(out, ",")
+}
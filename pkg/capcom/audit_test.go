@@ -0,0 +1,200 @@
+package capcom
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestAudit(t *testing.T) {
+	svc := &mockEC2Client{}
+	out, err := Audit(aws.BackgroundContext(), svc, AuditOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("Expected 1 finding, found %d: %v", len(out), out)
+	}
+	if out[0].Check != CheckMissingDescription {
+		t.Errorf("Unexpected check %s", out[0].Check)
+	}
+}
+
+func TestAuditSecurityGroups(t *testing.T) {
+	data := []struct {
+		name     string
+		sg       *ec2.SecurityGroup
+		used     bool
+		expected map[string]int
+	}{
+		{
+			name: "Clean group",
+			sg: &ec2.SecurityGroup{
+				GroupId:   aws.String("sg-1"),
+				GroupName: aws.String("clean"),
+				IpPermissions: []*ec2.IpPermission{
+					{
+						IpProtocol: aws.String("tcp"),
+						FromPort:   aws.Int64(443),
+						ToPort:     aws.Int64(443),
+						IpRanges: []*ec2.IpRange{
+							{
+								CidrIp:      aws.String("0.0.0.0/0"),
+								Description: aws.String("HTTPS"),
+							},
+						},
+					},
+				},
+			},
+			used:     true,
+			expected: map[string]int{},
+		},
+		{
+			name: "Unused group",
+			sg: &ec2.SecurityGroup{
+				GroupId:   aws.String("sg-1"),
+				GroupName: aws.String("unused"),
+			},
+			expected: map[string]int{CheckUnused: 1},
+		},
+		{
+			name: "Unused default group",
+			sg: &ec2.SecurityGroup{
+				GroupId:   aws.String("sg-1"),
+				GroupName: aws.String("default"),
+			},
+			expected: map[string]int{},
+		},
+		{
+			name: "World open SSH and wide range",
+			sg: &ec2.SecurityGroup{
+				GroupId:   aws.String("sg-1"),
+				GroupName: aws.String("open"),
+				IpPermissions: []*ec2.IpPermission{
+					{
+						IpProtocol: aws.String("tcp"),
+						FromPort:   aws.Int64(0),
+						ToPort:     aws.Int64(65535),
+						Ipv6Ranges: []*ec2.Ipv6Range{
+							{
+								CidrIpv6:    aws.String("::/0"),
+								Description: aws.String("All"),
+							},
+						},
+					},
+				},
+			},
+			used: true,
+			expected: map[string]int{
+				CheckWorldOpen:      1,
+				CheckBroadPortRange: 1,
+			},
+		},
+		{
+			name: "Dangling, shadowed and undescribed",
+			sg: &ec2.SecurityGroup{
+				GroupId:   aws.String("sg-1"),
+				GroupName: aws.String("messy"),
+				OwnerId:   aws.String("1234"),
+				IpPermissions: []*ec2.IpPermission{
+					{
+						IpProtocol: aws.String("tcp"),
+						FromPort:   aws.Int64(5432),
+						ToPort:     aws.Int64(5432),
+						IpRanges: []*ec2.IpRange{
+							{
+								CidrIp:      aws.String("10.0.0.0/8"),
+								Description: aws.String("Internal"),
+							},
+							{
+								CidrIp:      aws.String("10.1.0.0/16"),
+								Description: aws.String("Office"),
+							},
+						},
+						UserIdGroupPairs: []*ec2.UserIdGroupPair{
+							{
+								GroupId: aws.String("sg-deleted"),
+								UserId:  aws.String("1234"),
+							},
+							{
+								GroupId: aws.String("sg-other"),
+								UserId:  aws.String("5678"),
+							},
+						},
+					},
+				},
+			},
+			used: true,
+			expected: map[string]int{
+				CheckDanglingReference:  1,
+				CheckShadowedRule:       1,
+				CheckMissingDescription: 2,
+			},
+		},
+		{
+			name: "Duplicated rule",
+			sg: &ec2.SecurityGroup{
+				GroupId:   aws.String("sg-1"),
+				GroupName: aws.String("duplicated"),
+				IpPermissions: []*ec2.IpPermission{
+					{
+						IpProtocol: aws.String("tcp"),
+						FromPort:   aws.Int64(80),
+						ToPort:     aws.Int64(80),
+						IpRanges: []*ec2.IpRange{
+							{
+								CidrIp:      aws.String("10.0.0.0/8"),
+								Description: aws.String("Internal"),
+							},
+						},
+					},
+					{
+						IpProtocol: aws.String("6"),
+						FromPort:   aws.Int64(80),
+						ToPort:     aws.Int64(80),
+						IpRanges: []*ec2.IpRange{
+							{
+								CidrIp:      aws.String("10.0.0.0/8"),
+								Description: aws.String("Internal again"),
+							},
+						},
+					},
+				},
+			},
+			used:     true,
+			expected: map[string]int{CheckDuplicateRule: 1},
+		},
+	}
+	for _, tc := range data {
+		t.Run(
+			tc.name,
+			func(t *testing.T) {
+				used := map[string]bool{"sg-1": tc.used}
+				out := auditSecurityGroups(
+					[]*ec2.SecurityGroup{tc.sg},
+					used,
+					AuditOptions{},
+				)
+				found := map[string]int{}
+				for _, f := range out {
+					found[f.Check]++
+				}
+				if len(found) != len(tc.expected) {
+					t.Errorf("Unexpected findings %v", out)
+				}
+				for check, count := range tc.expected {
+					if found[check] != count {
+						t.Errorf(
+							"Expected %d %s findings, found %d: %v",
+							count,
+							check,
+							found[check],
+							out,
+						)
+					}
+				}
+			},
+		)
+	}
+}
@@ -13,80 +13,68 @@ import (
 )
 
 // CreateSG creates a new security group. If a vpcid is specified the security
-// group will be in that VPC
+// group will be in that VPC, and tags are added to it if any
 func CreateSG(
+	ctx aws.Context,
 	name string,
 	description string,
 	vpcid string,
+	tags map[string]string,
 	svc ec2iface.EC2API,
-) string {
-	if description == "" {
-		log.Fatal("Not a valid description")
-	}
-	params := &ec2.CreateSecurityGroupInput{
-		Description: aws.String(description),
-		GroupName:   aws.String(name),
-	}
-	if vpcid != "" {
-		params.VpcId = aws.String(vpcid)
-	}
-	if err := params.Validate(); err != nil {
-		log.Panic(err.Error())
-	}
-	res, err := svc.CreateSecurityGroup(params)
-	if err != nil {
-		log.Panic(err.Error())
-	}
-	return *res.GroupId
+) (string, error) {
+	return NewClient(svc).CreateSecurityGroup(
+		ctx,
+		name,
+		description,
+		vpcid,
+		tags,
+	)
 }
 
 // AuthorizeAccessToSecurityGroup adds the specified permissions to the Ingress
-// list of the destination security group on protocol and port
+// list of the destination security group on protocol and port. It
+// succeeds if the permissions were already there
 func AuthorizeAccessToSecurityGroup(
+	ctx aws.Context,
 	svc ec2iface.EC2API,
 	perm *ec2.IpPermission,
 	destination string,
-) bool {
-	out, error := svc.AuthorizeSecurityGroupIngress(
-		&ec2.AuthorizeSecurityGroupIngressInput{
-			GroupId:       &destination,
-			IpPermissions: []*ec2.IpPermission{perm},
-		})
-	if error != nil {
-		log.Panic(error)
-	}
-	if !(out != nil) {
-		return false
-	}
-	return true
+) error {
+	return NewClient(svc).Authorize(ctx, destination, perm)
 }
 
-// RevokeAccessToSecurityGroup adds the specified permissions to the Ingress
-// list of the destination security group on protocol and port
+// RevokeAccessToSecurityGroup removes the specified permissions from the
+// Ingress list of the destination security group on protocol and port. It
+// succeeds if the permissions weren't there
 func RevokeAccessToSecurityGroup(
+	ctx aws.Context,
 	svc ec2iface.EC2API,
 	perm *ec2.IpPermission,
 	destination string,
-) bool {
-	out, error := svc.RevokeSecurityGroupIngress(
-		&ec2.RevokeSecurityGroupIngressInput{
-			GroupId:       &destination,
-			IpPermissions: []*ec2.IpPermission{perm},
-		})
-	if error != nil {
-		log.Panic(error)
-	}
-	if !(out != nil) {
-		return false
-	}
-	return true
+) error {
+	return NewClient(svc).Revoke(ctx, destination, perm)
 }
 
 // FindSecurityGroupsWithRange returns a list of SGIDs where the CIDR
 // passed in matches any of the rules
 func FindSecurityGroupsWithRange(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+	cidr string,
+) (
+	out []SearchResult,
+	err error,
+) {
+	return FindFilteredSecurityGroupsWithRange(ctx, svc, cidr, GroupFilter{})
+}
+
+// FindFilteredSecurityGroupsWithRange returns a list of SGIDs, among the
+// ones matching filter, where the CIDR passed in matches any of the rules
+func FindFilteredSecurityGroupsWithRange(
+	ctx aws.Context,
 	svc ec2iface.EC2API,
 	cidr string,
+	filter GroupFilter,
 ) (
 	out []SearchResult,
 	err error,
@@ -98,7 +86,11 @@ func FindSecurityGroupsWithRange(
 		return
 	}
 	// Obtain and traverse AWS's Security Group structure
-	for _, sg := range getSecurityGroups(svc).SecurityGroups {
+	sglist, err := filterSecurityGroups(ctx, svc, filter.filters())
+	if err != nil {
+		return
+	}
+	for _, sg := range sglist {
 		for _, perm := range sg.IpPermissions {
 			for _, ipRange := range perm.IpRanges {
 				cont, err := NetworkContainsIPCheck(
@@ -115,10 +107,11 @@ func FindSecurityGroupsWithRange(
 				}
 				if cont {
 					out = append(out, SearchResult{
-						GroupID:  *sg.GroupId,
-						Protocol: *perm.IpProtocol,
-						Port:     *perm.ToPort,
-						Source:   *ipRange.CidrIp,
+						GroupID:     *sg.GroupId,
+						Protocol:    *perm.IpProtocol,
+						Port:        *perm.ToPort,
+						Source:      *ipRange.CidrIp,
+						Description: aws.StringValue(ipRange.Description),
 					})
 				}
 			}
@@ -128,23 +121,68 @@ func FindSecurityGroupsWithRange(
 }
 
 // getSecurityGroups retrieves the list of all Security Groups in the account
-func getSecurityGroups(svc ec2iface.EC2API) *ec2.DescribeSecurityGroupsOutput {
-	res, err := svc.DescribeSecurityGroups(nil)
+func getSecurityGroups(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+) (*ec2.DescribeSecurityGroupsOutput, error) {
+	sglist, err := filterSecurityGroups(ctx, svc, nil)
 	if err != nil {
-		log.Panic(err)
+		return nil, err
+	}
+	return &ec2.DescribeSecurityGroupsOutput{SecurityGroups: sglist}, nil
+}
+
+// filterSecurityGroups retrieves all the Security Groups in the account
+// matching filters, following pagination
+func filterSecurityGroups(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+	filters []*ec2.Filter,
+) (out []*ec2.SecurityGroup, err error) {
+	params := &ec2.DescribeSecurityGroupsInput{Filters: filters}
+	for {
+		res, err := svc.DescribeSecurityGroupsWithContext(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, res.SecurityGroups...)
+		if res.NextToken == nil {
+			return out, nil
+		}
+		params.NextToken = res.NextToken
 	}
-	return res
 }
 
 // ListSecurityGroups prints all available Security groups accessible
 // by the account on svc
-func ListSecurityGroups(svc ec2iface.EC2API) (out []string) {
-	for _, sg := range getSecurityGroups(svc).SecurityGroups {
-		out = append(out, fmt.Sprintf("* %10s %20s %s\n",
+func ListSecurityGroups(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+) (out []string, err error) {
+	return ListFilteredSecurityGroups(ctx, svc, GroupFilter{})
+}
+
+// ListFilteredSecurityGroups prints the Security groups accessible by
+// the account on svc matching filter, with their tags if any
+func ListFilteredSecurityGroups(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+	filter GroupFilter,
+) (out []string, err error) {
+	sglist, err := filterSecurityGroups(ctx, svc, filter.filters())
+	if err != nil {
+		return
+	}
+	for _, sg := range sglist {
+		line := fmt.Sprintf("* %10s %20s %s",
 			*sg.GroupId,
 			*sg.GroupName,
-			*sg.Description),
+			*sg.Description,
 		)
+		if len(sg.Tags) > 0 {
+			line = fmt.Sprintf("%s [%s]", line, formatTags(sg.Tags))
+		}
+		out = append(out, line+"\n")
 	}
 	return
 }
@@ -164,11 +202,13 @@ func NetworkContainsIPCheck(cidr string, searchIP net.IP) (out bool, err error)
 	return
 }
 
-// BuildIPPermission provides an IpPermission object fully populated
+// BuildIPPermission provides an IpPermission object fully populated. The
+// description is optional
 func BuildIPPermission(
 	origin string,
 	proto string,
 	port int64,
+	description string,
 ) (
 	perm *ec2.IpPermission,
 	err error,
@@ -178,14 +218,20 @@ func BuildIPPermission(
 		ToPort:     &port,
 		IpProtocol: &proto,
 	}
+	var desc *string
+	if description != "" {
+		desc = aws.String(description)
+	}
 	switch {
 	case strings.HasPrefix(origin, "sg-"):
 		// It's a security group
-		perm.UserIdGroupPairs = []*ec2.UserIdGroupPair{{GroupId: &origin}}
+		perm.UserIdGroupPairs = []*ec2.UserIdGroupPair{
+			{GroupId: &origin, Description: desc},
+		}
 
 	case isCIDR(origin):
 		// It's a valid CIDR
-		perm.IpRanges = []*ec2.IpRange{{CidrIp: &origin}}
+		perm.IpRanges = []*ec2.IpRange{{CidrIp: &origin, Description: desc}}
 	default:
 		err = fmt.Errorf(
 			"%s is neither sgid nor IP range in CIDR notation",
@@ -203,29 +249,20 @@ func isCIDR(origin string) bool {
 	return true
 }
 
-// FindSGByName gets an array of sgids for a name search
-func FindSGByName(name string, vpc string, svc ec2iface.EC2API) (ret []string) {
-	res, err := svc.DescribeSecurityGroups(
-		&ec2.DescribeSecurityGroupsInput{
-			Filters: []*ec2.Filter{
-				&ec2.Filter{
-					Name:   aws.String("group-name"),
-					Values: []*string{&name},
-				},
-			},
-		})
-	if err != nil {
-		log.Panic(err.Error())
-	}
-	for _, sg := range res.SecurityGroups {
-		ret = append(ret, *sg.GroupId)
-	}
-	return ret
+// FindSGByName gets an array of sgids for a name search, restricted to
+// a VPC if vpc is not empty
+func FindSGByName(
+	ctx aws.Context,
+	name string,
+	vpc string,
+	svc ec2iface.EC2API,
+) ([]string, error) {
+	return NewClient(svc).FindByName(ctx, name, vpc)
 }
 
-// Init initializes connection to AWS API
+// Init initializes connection to AWS API in the default region
 func Init() ec2iface.EC2API {
-	region := "us-east-1"
+	region := DefaultRegion
 	return ec2.New(
 		session.New(
 			&aws.Config{
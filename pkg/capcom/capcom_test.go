@@ -5,11 +5,18 @@ import (
 	"fmt"
 	"net"
 	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
 )
 
 func TestListSecurityGroups(t *testing.T) {
 	svc := &mockEC2Client{}
-	out := ListSecurityGroups(svc)
+	out, err := ListSecurityGroups(aws.BackgroundContext(), svc)
+	if err != nil {
+		t.Fatal(err)
+	}
 	expected := []string{
 		fmt.Sprintf("* %10s %20s %s\n", "sg-1234", "", ""),
 	}
@@ -63,6 +70,7 @@ func TestBuildIPPermission(t *testing.T) {
 			tc.origin,
 			tc.proto,
 			tc.port,
+			"",
 		)
 		if (err != nil && tc.err == nil) ||
 			(err == nil && tc.err != nil) {
@@ -76,6 +84,7 @@ func TestCreateSG(t *testing.T) {
 		name        string
 		description string
 		vpcid       string
+		tags        map[string]string
 		out         string
 	}{
 		{
@@ -90,6 +99,13 @@ func TestCreateSG(t *testing.T) {
 			vpcid:       "vpc-12345678",
 			out:         "sg-12345678",
 		},
+		{
+			name:        "",
+			description: "Tagged success",
+			vpcid:       "vpc-12345678",
+			tags:        map[string]string{"env": "test"},
+			out:         "sg-12345678",
+		},
 	}
 
 	svc := &mockEC2Client{}
@@ -97,12 +113,17 @@ func TestCreateSG(t *testing.T) {
 		t.Run(
 			tc.description,
 			func(t *testing.T) {
-				out := CreateSG(
+				out, err := CreateSG(
+					aws.BackgroundContext(),
 					tc.name,
 					tc.description,
 					tc.vpcid,
+					tc.tags,
 					svc,
 				)
+				if err != nil {
+					t.Fatal(err)
+				}
 				if out != tc.out {
 					t.Error("Unexpected output")
 				}
@@ -127,7 +148,10 @@ func TestFindSGByName(t *testing.T) {
 	}
 	svc := &mockEC2Client{}
 	for _, tc := range data {
-		ret := FindSGByName(tc.name, tc.vpc, svc)
+		ret, err := FindSGByName(aws.BackgroundContext(), tc.name, tc.vpc, svc)
+		if err != nil {
+			t.Fatal(err)
+		}
 		for index := range ret {
 			if ret[index] != tc.ret[index] {
 				t.Error("Unexpected output")
@@ -157,7 +181,7 @@ func TestFindSecurityGroupsWithRange(t *testing.T) {
 
 	svc := &mockEC2Client{}
 	for _, tc := range data {
-		ret, err := FindSecurityGroupsWithRange(svc, tc.cidr)
+		ret, err := FindSecurityGroupsWithRange(aws.BackgroundContext(), svc, tc.cidr)
 		if (err != nil && tc.err == nil) ||
 			(err == nil && tc.err != nil) {
 			t.Error("Unexpected/mismatched error")
@@ -256,13 +280,14 @@ func TestAuthorizeAccessToSecurityGroup(t *testing.T) {
 	}
 	svc := &mockEC2Client{}
 	for _, tc := range data {
-		perm, _ := BuildIPPermission(tc.origin, tc.proto, tc.port)
-		out := AuthorizeAccessToSecurityGroup(
+		perm, _ := BuildIPPermission(tc.origin, tc.proto, tc.port, "")
+		err := AuthorizeAccessToSecurityGroup(
+			aws.BackgroundContext(),
 			svc,
 			perm,
 			tc.destination,
 		)
-		if out != tc.expected {
+		if (err == nil) != tc.expected {
 			t.Error("Unexpected mismatch")
 		}
 	}
@@ -284,15 +309,73 @@ func TestRevokeAccessToSecurityGroup(t *testing.T) {
 	}
 	svc := &mockEC2Client{}
 	for _, tc := range data {
-		perm, _ := BuildIPPermission(tc.origin, tc.proto, tc.port)
-		out := RevokeAccessToSecurityGroup(
+		perm, _ := BuildIPPermission(tc.origin, tc.proto, tc.port, "")
+		err := RevokeAccessToSecurityGroup(
+			aws.BackgroundContext(),
 			svc,
 			perm,
 			tc.destination,
 		)
-		if out != tc.expected {
+		if (err == nil) != tc.expected {
 			t.Error("Unexpected mismatch")
 		}
 	}
 
 }
+
+type pagingEC2Client struct {
+	mockEC2Client
+	calls int
+}
+
+func (m *pagingEC2Client) DescribeSecurityGroupsWithContext(
+	ctx aws.Context,
+	in *ec2.DescribeSecurityGroupsInput,
+	opts ...request.Option,
+) (
+	out *ec2.DescribeSecurityGroupsOutput,
+	err error,
+) {
+	m.calls++
+	out = &ec2.DescribeSecurityGroupsOutput{
+		SecurityGroups: []*ec2.SecurityGroup{
+			{GroupId: aws.String(fmt.Sprintf("sg-%d", m.calls))},
+		},
+	}
+	if in.NextToken == nil {
+		out.NextToken = aws.String("next")
+	}
+	return
+}
+
+func TestFilterSecurityGroupsPagination(t *testing.T) {
+	svc := &pagingEC2Client{}
+	out, err := filterSecurityGroups(aws.BackgroundContext(), svc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 || svc.calls != 2 {
+		t.Errorf("Expected 2 pages, got %d groups in %d calls", len(out), svc.calls)
+	}
+}
+
+func TestBuildIPPermissionDescription(t *testing.T) {
+	perm, err := BuildIPPermission("sg-1234", "tcp", 22, "Bastion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *perm.UserIdGroupPairs[0].Description != "Bastion" {
+		t.Error("Missing description in group pair")
+	}
+	perm, err = BuildIPPermission("1.2.3.4/32", "tcp", 22, "Office")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *perm.IpRanges[0].Description != "Office" {
+		t.Error("Missing description in IP range")
+	}
+	perm, _ = BuildIPPermission("1.2.3.4/32", "tcp", 22, "")
+	if perm.IpRanges[0].Description != nil {
+		t.Error("Unexpected description in IP range")
+	}
+}
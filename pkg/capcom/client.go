@@ -0,0 +1,136 @@
+package capcom
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// Client manages Security Groups through an EC2 API endpoint. All its
+// operations honour the deadline and cancellation of the context they
+// get, and return DuplicateGroupError, NotFoundError or
+// LimitExceededError when AWS rejects them for those reasons.
+type Client struct {
+	svc ec2iface.EC2API
+}
+
+// NewClient returns a Client working on svc.
+func NewClient(svc ec2iface.EC2API) *Client {
+	return &Client{svc: svc}
+}
+
+// CreateSecurityGroup creates a new Security Group and returns its
+// sgid. If a vpcid is specified the group will be in that VPC, and tags
+// are added to it if any.
+func (c *Client) CreateSecurityGroup(
+	ctx aws.Context,
+	name string,
+	description string,
+	vpcid string,
+	tags map[string]string,
+) (string, error) {
+	if description == "" {
+		return "", fmt.Errorf("Not a valid description")
+	}
+	params := &ec2.CreateSecurityGroupInput{
+		Description: aws.String(description),
+		GroupName:   aws.String(name),
+	}
+	if vpcid != "" {
+		params.VpcId = aws.String(vpcid)
+	}
+	if err := params.Validate(); err != nil {
+		return "", err
+	}
+	res, err := c.svc.CreateSecurityGroupWithContext(ctx, params)
+	if err != nil {
+		return "", classifyError(name, err)
+	}
+	if len(tags) > 0 {
+		if _, err := c.svc.CreateTagsWithContext(ctx, &ec2.CreateTagsInput{
+			Resources: []*string{res.GroupId},
+			Tags:      buildTags(tags),
+		}); err != nil {
+			return "", classifyError(*res.GroupId, err)
+		}
+	}
+	return *res.GroupId, nil
+}
+
+// Authorize adds perm to the ingress rules of sgid. Authorizing a rule
+// which already exists succeeds without changes.
+func (c *Client) Authorize(
+	ctx aws.Context,
+	sgid string,
+	perm *ec2.IpPermission,
+) error {
+	_, err := c.svc.AuthorizeSecurityGroupIngressWithContext(
+		ctx,
+		&ec2.AuthorizeSecurityGroupIngressInput{
+			GroupId:       aws.String(sgid),
+			IpPermissions: []*ec2.IpPermission{perm},
+		},
+	)
+	if hasCode(err, codeDuplicatePermission) {
+		log.Printf("Rule already present in %s\n", sgid)
+		return nil
+	}
+	if err != nil {
+		return classifyError(sgid, err)
+	}
+	return nil
+}
+
+// Revoke removes perm from the ingress rules of sgid. Revoking a rule
+// which doesn't exist succeeds without changes, but revoking from a
+// missing group returns a NotFoundError.
+func (c *Client) Revoke(
+	ctx aws.Context,
+	sgid string,
+	perm *ec2.IpPermission,
+) error {
+	_, err := c.svc.RevokeSecurityGroupIngressWithContext(
+		ctx,
+		&ec2.RevokeSecurityGroupIngressInput{
+			GroupId:       aws.String(sgid),
+			IpPermissions: []*ec2.IpPermission{perm},
+		},
+	)
+	if hasCode(err, codePermissionNotFound) {
+		log.Printf("Rule not present in %s\n", sgid)
+		return nil
+	}
+	if err != nil {
+		return classifyError(sgid, err)
+	}
+	return nil
+}
+
+// SecurityGroups retrieves all the Security Groups matching filter,
+// following pagination.
+func (c *Client) SecurityGroups(
+	ctx aws.Context,
+	filter GroupFilter,
+) ([]*ec2.SecurityGroup, error) {
+	return filterSecurityGroups(ctx, c.svc, filter.filters())
+}
+
+// FindByName returns the sgids of the groups called name, restricted
+// to a VPC if vpcid is not empty.
+func (c *Client) FindByName(
+	ctx aws.Context,
+	name string,
+	vpcid string,
+) (out []string, err error) {
+	sglist, err := c.SecurityGroups(ctx, GroupFilter{VpcID: vpcid, Name: name})
+	if err != nil {
+		return
+	}
+	for _, sg := range sglist {
+		out = append(out, *sg.GroupId)
+	}
+	return
+}
@@ -0,0 +1,182 @@
+package capcom
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+type failingEC2Client struct {
+	mockEC2Client
+	err error
+}
+
+func (m *failingEC2Client) AuthorizeSecurityGroupIngressWithContext(
+	ctx aws.Context,
+	params *ec2.AuthorizeSecurityGroupIngressInput,
+	opts ...request.Option,
+) (
+	*ec2.AuthorizeSecurityGroupIngressOutput,
+	error,
+) {
+	return nil, m.err
+}
+
+func (m *failingEC2Client) RevokeSecurityGroupIngressWithContext(
+	ctx aws.Context,
+	params *ec2.RevokeSecurityGroupIngressInput,
+	opts ...request.Option,
+) (
+	*ec2.RevokeSecurityGroupIngressOutput,
+	error,
+) {
+	return nil, m.err
+}
+
+func (m *failingEC2Client) CreateSecurityGroupWithContext(
+	ctx aws.Context,
+	params *ec2.CreateSecurityGroupInput,
+	opts ...request.Option,
+) (
+	*ec2.CreateSecurityGroupOutput,
+	error,
+) {
+	return nil, m.err
+}
+
+func (m *failingEC2Client) DescribeSecurityGroupsWithContext(
+	ctx aws.Context,
+	params *ec2.DescribeSecurityGroupsInput,
+	opts ...request.Option,
+) (
+	*ec2.DescribeSecurityGroupsOutput,
+	error,
+) {
+	return nil, m.err
+}
+
+func TestClientAuthorize(t *testing.T) {
+	data := []struct {
+		name  string
+		err   error
+		check func(error) bool
+	}{
+		{
+			name:  "Success",
+			check: func(err error) bool { return err == nil },
+		},
+		{
+			name:  "Duplicate rule",
+			err:   awserr.New(codeDuplicatePermission, "", nil),
+			check: func(err error) bool { return err == nil },
+		},
+		{
+			name: "Missing group",
+			err:  awserr.New("InvalidGroup.NotFound", "", nil),
+			check: func(err error) bool {
+				_, ok := err.(*NotFoundError)
+				return ok
+			},
+		},
+		{
+			name: "Too many rules",
+			err:  awserr.New("RulesPerSecurityGroupLimitExceeded", "", nil),
+			check: func(err error) bool {
+				_, ok := err.(*LimitExceededError)
+				return ok
+			},
+		},
+		{
+			name:  "Other errors",
+			err:   errors.New("connection reset"),
+			check: func(err error) bool { return err.Error() == "connection reset" },
+		},
+	}
+	perm, _ := BuildIPPermission("1.2.3.4/32", "tcp", 22, "")
+	for _, tc := range data {
+		t.Run(
+			tc.name,
+			func(t *testing.T) {
+				client := NewClient(&mockEC2Client{})
+				if tc.err != nil {
+					client = NewClient(&failingEC2Client{err: tc.err})
+				}
+				err := client.Authorize(aws.BackgroundContext(), "sg-1234", perm)
+				if !tc.check(err) {
+					t.Errorf("Unexpected error %v", err)
+				}
+			},
+		)
+	}
+}
+
+func TestClientRevoke(t *testing.T) {
+	data := []struct {
+		name  string
+		err   error
+		check func(error) bool
+	}{
+		{
+			name:  "Missing rule",
+			err:   awserr.New(codePermissionNotFound, "", nil),
+			check: func(err error) bool { return err == nil },
+		},
+		{
+			name: "Missing group",
+			err:  awserr.New("InvalidGroup.NotFound", "", nil),
+			check: func(err error) bool {
+				e, ok := err.(*NotFoundError)
+				return ok && e.ID == "sg-1234"
+			},
+		},
+	}
+	perm, _ := BuildIPPermission("1.2.3.4/32", "tcp", 22, "")
+	for _, tc := range data {
+		client := NewClient(&failingEC2Client{err: tc.err})
+		err := client.Revoke(aws.BackgroundContext(), "sg-1234", perm)
+		if !tc.check(err) {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		}
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	client := NewClient(&failingEC2Client{
+		err: awserr.New("SecurityGroupLimitExceeded", "", nil),
+	})
+	_, err := client.CreateSecurityGroup(
+		aws.BackgroundContext(),
+		"web",
+		"Web servers",
+		"",
+		nil,
+	)
+	if _, ok := err.(*LimitExceededError); !ok {
+		t.Errorf("Unexpected error %v", err)
+	}
+	client = NewClient(&failingEC2Client{
+		err: awserr.New(codeDuplicateGroup, "", nil),
+	})
+	_, err = client.CreateSecurityGroup(
+		aws.BackgroundContext(),
+		"web",
+		"Web servers",
+		"",
+		nil,
+	)
+	if e, ok := err.(*DuplicateGroupError); !ok || e.Name != "web" {
+		t.Errorf("Unexpected error %v", err)
+	}
+	_, err = client.CreateSecurityGroup(aws.BackgroundContext(), "web", "", "", nil)
+	if err == nil {
+		t.Error("Expected error for empty description")
+	}
+	_, err = client.FindByName(aws.BackgroundContext(), "web", "")
+	if err == nil {
+		t.Error("Expected error listing groups")
+	}
+}
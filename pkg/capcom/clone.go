@@ -0,0 +1,321 @@
+package capcom
+
+import (
+	"log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// CloneOptions defines where and how Security Groups are cloned.
+// Mapping translates references to groups not being cloned into the
+// sgids to use in the destination, and references to prefix lists into
+// the ones of the destination region. Name is only honoured when
+// cloning a single group. CrossRegion must be set when the destination
+// is in another region, as prefix lists are regional and need a
+// mapping there.
+type CloneOptions struct {
+	VpcID       string
+	Name        string
+	Mapping     map[string]string
+	CrossRegion bool
+}
+
+// CloneResult reports the sgids of the clones, and the rules which
+// couldn't be cloned because they reference unmapped groups.
+type CloneResult struct {
+	Mapping map[string]string
+	Skipped []Rule
+}
+
+// CloneSecurityGroups recreates the groups sgids, and all their rules,
+// from src into the VPC in dst. References between cloned groups point
+// to their clones. If any step fails, the clones created so far are
+// deleted.
+func CloneSecurityGroups(
+	ctx aws.Context,
+	src ec2iface.EC2API,
+	dst ec2iface.EC2API,
+	sgids []string,
+	opts CloneOptions,
+) (
+	out CloneResult,
+	err error,
+) {
+	res, err := src.DescribeSecurityGroupsWithContext(
+		ctx,
+		&ec2.DescribeSecurityGroupsInput{
+			GroupIds: aws.StringSlice(sgids),
+		},
+	)
+	if err != nil {
+		return
+	}
+	out.Mapping = make(map[string]string)
+	for k, v := range opts.Mapping {
+		out.Mapping[k] = v
+	}
+	var created []string
+	defer func() {
+		if err != nil {
+			deleteClones(dst, created)
+		}
+	}()
+	for _, sg := range res.SecurityGroups {
+		name := aws.StringValue(sg.GroupName)
+		if opts.Name != "" && len(res.SecurityGroups) == 1 {
+			name = opts.Name
+		}
+		var sgid string
+		sgid, err = createClone(ctx, dst, sg, name, opts.VpcID)
+		if sgid != "" {
+			created = append(created, sgid)
+		}
+		if err != nil {
+			return
+		}
+		log.Printf("Cloned %s into %s\n", aws.StringValue(sg.GroupId), sgid)
+		out.Mapping[aws.StringValue(sg.GroupId)] = sgid
+	}
+	for _, sg := range res.SecurityGroups {
+		var skipped []Rule
+		skipped, err = cloneRules(ctx, dst, sg, out.Mapping, opts)
+		out.Skipped = append(out.Skipped, skipped...)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// deleteClones removes the clones sgids after a failed clone. Their
+// rules are revoked first, as groups referenced by others can't be
+// deleted. It doesn't honour the context of the clone, as the clones
+// must go even when it was cancelled, and failures are only logged.
+func deleteClones(svc ec2iface.EC2API, sgids []string) {
+	if len(sgids) == 0 {
+		return
+	}
+	ctx := aws.BackgroundContext()
+	res, err := svc.DescribeSecurityGroupsWithContext(
+		ctx,
+		&ec2.DescribeSecurityGroupsInput{GroupIds: aws.StringSlice(sgids)},
+	)
+	if err != nil {
+		log.Printf("Failed to describe clones %v: %s\n", sgids, err)
+		return
+	}
+	for _, sg := range res.SecurityGroups {
+		if len(sg.IpPermissions) > 0 {
+			if _, err := svc.RevokeSecurityGroupIngressWithContext(
+				ctx,
+				&ec2.RevokeSecurityGroupIngressInput{
+					GroupId:       sg.GroupId,
+					IpPermissions: sg.IpPermissions,
+				},
+			); err != nil {
+				log.Printf("Failed to revoke rules of %s: %s\n", *sg.GroupId, err)
+			}
+		}
+		if len(sg.IpPermissionsEgress) > 0 {
+			if _, err := svc.RevokeSecurityGroupEgressWithContext(
+				ctx,
+				&ec2.RevokeSecurityGroupEgressInput{
+					GroupId:       sg.GroupId,
+					IpPermissions: sg.IpPermissionsEgress,
+				},
+			); err != nil {
+				log.Printf("Failed to revoke rules of %s: %s\n", *sg.GroupId, err)
+			}
+		}
+	}
+	for _, sgid := range sgids {
+		if _, err := svc.DeleteSecurityGroupWithContext(
+			ctx,
+			&ec2.DeleteSecurityGroupInput{GroupId: aws.String(sgid)},
+		); err != nil {
+			log.Printf("Failed to delete clone %s: %s\n", sgid, err)
+			continue
+		}
+		log.Printf("Deleted clone %s\n", sgid)
+	}
+}
+
+// createClone creates a group like sg, with its tags but the reserved
+// ones, set by services like CloudFormation. If tagging fails
+// the sgid of the new group is returned along with the error.
+func createClone(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+	sg *ec2.SecurityGroup,
+	name string,
+	vpcid string,
+) (string, error) {
+	params := &ec2.CreateSecurityGroupInput{
+		Description: sg.Description,
+		GroupName:   aws.String(name),
+	}
+	if vpcid != "" {
+		params.VpcId = aws.String(vpcid)
+	}
+	res, err := svc.CreateSecurityGroupWithContext(ctx, params)
+	if err != nil {
+		return "", err
+	}
+	if tags := userTags(sg.Tags); len(tags) > 0 {
+		if _, err := svc.CreateTagsWithContext(ctx, &ec2.CreateTagsInput{
+			Resources: []*string{res.GroupId},
+			Tags:      tags,
+		}); err != nil {
+			return aws.StringValue(res.GroupId), err
+		}
+	}
+	return aws.StringValue(res.GroupId), nil
+}
+
+// cloneRules authorizes the rules of sg on its clone. For VPC clones,
+// the default egress rule is revoked first so egress matches sg.
+func cloneRules(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+	sg *ec2.SecurityGroup,
+	mapping map[string]string,
+	opts CloneOptions,
+) (
+	skipped []Rule,
+	err error,
+) {
+	sgid := mapping[aws.StringValue(sg.GroupId)]
+	ingress, skippedIngress := remapPermissions(
+		sg,
+		sg.IpPermissions,
+		false,
+		mapping,
+		opts.CrossRegion,
+	)
+	egress, skippedEgress := remapPermissions(
+		sg,
+		sg.IpPermissionsEgress,
+		true,
+		mapping,
+		opts.CrossRegion,
+	)
+	skipped = append(skippedIngress, skippedEgress...)
+	if len(ingress) > 0 {
+		if _, err = svc.AuthorizeSecurityGroupIngressWithContext(
+			ctx,
+			&ec2.AuthorizeSecurityGroupIngressInput{
+				GroupId:       aws.String(sgid),
+				IpPermissions: ingress,
+			},
+		); err != nil {
+			return
+		}
+	}
+	if opts.VpcID == "" {
+		return
+	}
+	if _, err = svc.RevokeSecurityGroupEgressWithContext(
+		ctx,
+		&ec2.RevokeSecurityGroupEgressInput{
+			GroupId:       aws.String(sgid),
+			IpPermissions: []*ec2.IpPermission{defaultEgress()},
+		},
+	); err != nil {
+		return
+	}
+	if len(egress) > 0 {
+		_, err = svc.AuthorizeSecurityGroupEgressWithContext(
+			ctx,
+			&ec2.AuthorizeSecurityGroupEgressInput{
+				GroupId:       aws.String(sgid),
+				IpPermissions: egress,
+			},
+		)
+	}
+	return
+}
+
+// defaultEgress returns the egress rule AWS adds to new VPC groups.
+func defaultEgress() *ec2.IpPermission {
+	return &ec2.IpPermission{
+		IpProtocol: aws.String(allProtocols),
+		IpRanges: []*ec2.IpRange{
+			{CidrIp: aws.String("0.0.0.0/0")},
+		},
+	}
+}
+
+// remapPermissions copies perms replacing group references through
+// mapping. References to unmapped groups are dropped and returned as
+// skipped rules. Prefix lists are replaced through mapping too, and,
+// when crossRegion, the unmapped ones are skipped as well.
+func remapPermissions(
+	sg *ec2.SecurityGroup,
+	perms []*ec2.IpPermission,
+	egress bool,
+	mapping map[string]string,
+	crossRegion bool,
+) (
+	out []*ec2.IpPermission,
+	skipped []Rule,
+) {
+	for _, perm := range perms {
+		clone := &ec2.IpPermission{
+			IpProtocol: perm.IpProtocol,
+			FromPort:   perm.FromPort,
+			ToPort:     perm.ToPort,
+			IpRanges:   perm.IpRanges,
+			Ipv6Ranges: perm.Ipv6Ranges,
+		}
+		skip := func(peer string) {
+			rule := Rule{
+				GroupID:  aws.StringValue(sg.GroupId),
+				Egress:   egress,
+				Protocol: aws.StringValue(perm.IpProtocol),
+				FromPort: aws.Int64Value(perm.FromPort),
+				ToPort:   aws.Int64Value(perm.ToPort),
+				Peer:     peer,
+			}
+			log.Printf("Skipping %s: no mapping for %s\n", rule, rule.Peer)
+			skipped = append(skipped, rule)
+		}
+		for _, list := range perm.PrefixListIds {
+			plid, ok := mapping[aws.StringValue(list.PrefixListId)]
+			if !ok {
+				if crossRegion {
+					skip(aws.StringValue(list.PrefixListId))
+					continue
+				}
+				plid = aws.StringValue(list.PrefixListId)
+			}
+			clone.PrefixListIds = append(
+				clone.PrefixListIds,
+				&ec2.PrefixListId{
+					PrefixListId: aws.String(plid),
+					Description:  list.Description,
+				},
+			)
+		}
+		for _, pair := range perm.UserIdGroupPairs {
+			sgid, ok := mapping[aws.StringValue(pair.GroupId)]
+			if !ok {
+				skip(aws.StringValue(pair.GroupId))
+				continue
+			}
+			clone.UserIdGroupPairs = append(
+				clone.UserIdGroupPairs,
+				&ec2.UserIdGroupPair{
+					GroupId:     aws.String(sgid),
+					Description: pair.Description,
+				},
+			)
+		}
+		if len(clone.IpRanges)+len(clone.Ipv6Ranges)+
+			len(clone.PrefixListIds)+len(clone.UserIdGroupPairs) > 0 {
+			out = append(out, clone)
+		}
+	}
+	return
+}
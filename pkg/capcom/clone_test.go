@@ -0,0 +1,213 @@
+package capcom
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestCloneSecurityGroups(t *testing.T) {
+	svc := &mockEC2Client{}
+	out, err := CloneSecurityGroups(
+		aws.BackgroundContext(),
+		svc,
+		svc,
+		[]string{"sg-1234"},
+		CloneOptions{VpcID: "vpc-12345678"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Mapping["sg-1234"] != "sg-12345678" {
+		t.Errorf("Unexpected mapping %v", out.Mapping)
+	}
+	if len(out.Skipped) != 0 {
+		t.Errorf("Unexpected skipped rules %v", out.Skipped)
+	}
+}
+
+type failingCloneEC2Client struct {
+	mockEC2Client
+	deleted []string
+}
+
+func (m *failingCloneEC2Client) AuthorizeSecurityGroupIngressWithContext(
+	ctx aws.Context,
+	in *ec2.AuthorizeSecurityGroupIngressInput,
+	opts ...request.Option,
+) (
+	*ec2.AuthorizeSecurityGroupIngressOutput,
+	error,
+) {
+	return nil, errors.New("connection reset")
+}
+
+func (m *failingCloneEC2Client) DeleteSecurityGroupWithContext(
+	ctx aws.Context,
+	in *ec2.DeleteSecurityGroupInput,
+	opts ...request.Option,
+) (
+	*ec2.DeleteSecurityGroupOutput,
+	error,
+) {
+	m.deleted = append(m.deleted, aws.StringValue(in.GroupId))
+	return &ec2.DeleteSecurityGroupOutput{}, nil
+}
+
+func TestCloneSecurityGroupsRollback(t *testing.T) {
+	svc := &failingCloneEC2Client{}
+	_, err := CloneSecurityGroups(
+		aws.BackgroundContext(),
+		svc,
+		svc,
+		[]string{"sg-1234"},
+		CloneOptions{VpcID: "vpc-12345678"},
+	)
+	if err == nil {
+		t.Fatal("Expected error authorizing rules")
+	}
+	if len(svc.deleted) != 1 || svc.deleted[0] != "sg-12345678" {
+		t.Errorf("Unexpected deleted clones %v", svc.deleted)
+	}
+}
+
+type taggingCloneEC2Client struct {
+	mockEC2Client
+	tags []*ec2.Tag
+}
+
+func (m *taggingCloneEC2Client) CreateTagsWithContext(
+	ctx aws.Context,
+	in *ec2.CreateTagsInput,
+	opts ...request.Option,
+) (
+	*ec2.CreateTagsOutput,
+	error,
+) {
+	for _, tag := range in.Tags {
+		if strings.HasPrefix(aws.StringValue(tag.Key), "aws:") {
+			return nil, errors.New("InvalidParameterValue: reserved tag")
+		}
+	}
+	m.tags = in.Tags
+	return &ec2.CreateTagsOutput{}, nil
+}
+
+func TestCreateCloneReservedTags(t *testing.T) {
+	svc := &taggingCloneEC2Client{}
+	sg := &ec2.SecurityGroup{
+		Description: aws.String("web"),
+		GroupId:     aws.String("sg-1234"),
+		Tags: []*ec2.Tag{
+			{
+				Key:   aws.String("aws:cloudformation:stack-name"),
+				Value: aws.String("web"),
+			},
+			{Key: aws.String("env"), Value: aws.String("prod")},
+		},
+	}
+	sgid, err := createClone(aws.BackgroundContext(), svc, sg, "web", "vpc-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sgid != "sg-12345678" {
+		t.Errorf("Unexpected clone %s", sgid)
+	}
+	if formatTags(svc.tags) != "env=prod" {
+		t.Errorf("Unexpected tags %s", formatTags(svc.tags))
+	}
+}
+
+func TestRemapPermissions(t *testing.T) {
+	sg := &ec2.SecurityGroup{
+		GroupId: aws.String("sg-1"),
+		IpPermissions: []*ec2.IpPermission{
+			{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(5432),
+				ToPort:     aws.Int64(5432),
+				UserIdGroupPairs: []*ec2.UserIdGroupPair{
+					{
+						GroupId:     aws.String("sg-2"),
+						GroupName:   aws.String("app"),
+						UserId:      aws.String("1234"),
+						Description: aws.String("App servers"),
+					},
+					{GroupId: aws.String("sg-3")},
+				},
+			},
+			{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(22),
+				ToPort:     aws.Int64(22),
+				UserIdGroupPairs: []*ec2.UserIdGroupPair{
+					{GroupId: aws.String("sg-3")},
+				},
+			},
+		},
+	}
+	mapping := map[string]string{"sg-1": "sg-10", "sg-2": "sg-20"}
+	out, skipped := remapPermissions(sg, sg.IpPermissions, false, mapping, false)
+	if len(out) != 1 || len(out[0].UserIdGroupPairs) != 1 {
+		t.Fatalf("Unexpected permissions %v", out)
+	}
+	pair := out[0].UserIdGroupPairs[0]
+	if *pair.GroupId != "sg-20" ||
+		pair.UserId != nil ||
+		*pair.Description != "App servers" {
+		t.Errorf("Unexpected group pair %v", pair)
+	}
+	if len(skipped) != 2 {
+		t.Errorf("Expected 2 skipped rules, found %v", skipped)
+	}
+}
+
+func TestRemapPrefixLists(t *testing.T) {
+	sg := &ec2.SecurityGroup{
+		GroupId: aws.String("sg-1"),
+		IpPermissions: []*ec2.IpPermission{
+			{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(443),
+				ToPort:     aws.Int64(443),
+				PrefixListIds: []*ec2.PrefixListId{
+					{PrefixListId: aws.String("pl-1")},
+					{PrefixListId: aws.String("pl-2")},
+				},
+			},
+		},
+	}
+	mapping := map[string]string{"pl-1": "pl-10"}
+	data := []struct {
+		crossRegion bool
+		lists       []string
+		skipped     int
+	}{
+		{crossRegion: false, lists: []string{"pl-10", "pl-2"}},
+		{crossRegion: true, lists: []string{"pl-10"}, skipped: 1},
+	}
+	for _, tc := range data {
+		out, skipped := remapPermissions(
+			sg,
+			sg.IpPermissions,
+			false,
+			mapping,
+			tc.crossRegion,
+		)
+		if len(out) != 1 || len(out[0].PrefixListIds) != len(tc.lists) {
+			t.Fatalf("Unexpected permissions %v", out)
+		}
+		for i, list := range tc.lists {
+			if aws.StringValue(out[0].PrefixListIds[i].PrefixListId) != list {
+				t.Errorf("Unexpected prefix lists %v", out[0].PrefixListIds)
+			}
+		}
+		if len(skipped) != tc.skipped {
+			t.Errorf("Unexpected skipped rules %v", skipped)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package capcom
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector implements a Prometheus Collector to report the posture of
+// Security Groups
+type Collector struct {
+	// mu serializes scrapes, which reset and count on the same metrics.
+	mu             sync.Mutex
+	svc            ec2iface.EC2API
+	sensitivePorts []int64
+	rules          *prometheus.GaugeVec
+	worldOpen      *prometheus.GaugeVec
+	unused         prometheus.Gauge
+	instances      *prometheus.GaugeVec
+}
+
+// NewCollector creates a new Collector for the Security Groups in svc.
+// World open rules are counted for sensitivePorts, or
+// DefaultSensitivePorts if nil.
+func NewCollector(svc ec2iface.EC2API, sensitivePorts []int64) *Collector {
+	if sensitivePorts == nil {
+		sensitivePorts = DefaultSensitivePorts
+	}
+	return &Collector{
+		svc:            svc,
+		sensitivePorts: sensitivePorts,
+		rules: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "capcom",
+				Name:      "rules_count",
+				Help:      "Number of rules in a Security Group",
+			},
+			[]string{"group", "name", "direction"},
+		),
+		worldOpen: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "capcom",
+				Name:      "world_open_rules_count",
+				Help:      "Number of ingress rules opening a sensitive port to any address",
+			},
+			[]string{"port"},
+		),
+		unused: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: "capcom",
+				Name:      "unused_groups_count",
+				Help:      "Number of Security Groups not attached to any network interface",
+			},
+		),
+		instances: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "capcom",
+				Name:      "instances_count",
+				Help:      "Number of instances in a Security Group by state",
+			},
+			[]string{"group", "state"},
+		),
+	}
+}
+
+// Describe is a requirement for the Collector interface of Prometheus
+// that returns each exported metric's description to the Prometheus
+// middleware
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.rules.Describe(ch)
+	c.worldOpen.Describe(ch)
+	c.unused.Describe(ch)
+	c.instances.Describe(ch)
+}
+
+// Collect is a requirement for the Collector interface of Prometheus
+// that runs the queries to set the metrics values to be exported
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Prometheus doesn't give collectors a context to honour.
+	if err := c.runCollection(aws.BackgroundContext()); err != nil {
+		log.Printf("Failed collecting Security Group metrics: %s\n", err)
+		return
+	}
+
+	c.rules.Collect(ch)
+	c.worldOpen.Collect(ch)
+	c.unused.Collect(ch)
+	c.instances.Collect(ch)
+}
+
+func (c *Collector) runCollection(ctx aws.Context) error {
+	sglist, err := getSecurityGroups(ctx, c.svc)
+	if err != nil {
+		return err
+	}
+	used, err := getUsedSecurityGroups(ctx, c.svc)
+	if err != nil {
+		return err
+	}
+	instances, err := getInstances(ctx, c.svc)
+	if err != nil {
+		return err
+	}
+	c.update(sglist.SecurityGroups, used, getInstancesStates(instances.Reservations))
+	return nil
+}
+
+// update sets the metrics from the Security Groups, the set of the
+// ones in use and the states of their instances.
+func (c *Collector) update(
+	sglist []*ec2.SecurityGroup,
+	used map[string]bool,
+	states sGInstanceState,
+) {
+	c.rules.Reset()
+	c.worldOpen.Reset()
+	c.instances.Reset()
+	c.unused.Set(0)
+	for _, port := range c.sensitivePorts {
+		c.worldOpen.WithLabelValues(fmt.Sprintf("%d", port)).Set(0)
+	}
+	for _, sg := range sglist {
+		sgid := aws.StringValue(sg.GroupId)
+		name := aws.StringValue(sg.GroupName)
+		c.rules.WithLabelValues(sgid, name, "ingress").Set(0)
+		c.rules.WithLabelValues(sgid, name, "egress").Set(0)
+		for _, rule := range Rules(sg) {
+			if rule.Egress {
+				c.rules.WithLabelValues(sgid, name, "egress").Inc()
+				continue
+			}
+			c.rules.WithLabelValues(sgid, name, "ingress").Inc()
+			for _, port := range worldOpenPorts(rule, c.sensitivePorts) {
+				c.worldOpen.WithLabelValues(fmt.Sprintf("%d", port)).Inc()
+			}
+		}
+		if isUnused(sg, used) {
+			c.unused.Inc()
+		}
+		for state, count := range states[sgid] {
+			c.instances.WithLabelValues(sgid, state).Set(float64(count))
+		}
+	}
+}
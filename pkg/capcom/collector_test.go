@@ -0,0 +1,80 @@
+package capcom
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func gaugeValue(t *testing.T, g prometheus.Gauge) float64 {
+	m := &dto.Metric{}
+	if err := g.Write(m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetGauge().GetValue()
+}
+
+func TestCollectorUpdate(t *testing.T) {
+	sglist := []*ec2.SecurityGroup{
+		{
+			GroupId:   aws.String("sg-1"),
+			GroupName: aws.String("web"),
+			IpPermissions: []*ec2.IpPermission{
+				{
+					IpProtocol: aws.String("tcp"),
+					FromPort:   aws.Int64(22),
+					ToPort:     aws.Int64(22),
+					IpRanges: []*ec2.IpRange{
+						{CidrIp: aws.String("0.0.0.0/0")},
+						{CidrIp: aws.String("10.0.0.0/8")},
+					},
+				},
+			},
+			IpPermissionsEgress: []*ec2.IpPermission{defaultEgress()},
+		},
+		{
+			GroupId:   aws.String("sg-2"),
+			GroupName: aws.String("old"),
+		},
+		{
+			GroupId:   aws.String("sg-12345678"),
+			GroupName: aws.String("app"),
+		},
+	}
+	used := map[string]bool{"sg-1": true, "sg-12345678": true}
+	c := NewCollector(nil, []int64{22, 3306})
+	c.update(sglist, used, getInstancesStates(describeInstancesOutput.Reservations))
+
+	data := []struct {
+		name     string
+		gauge    prometheus.Gauge
+		expected float64
+	}{
+		{"Ingress rules", c.rules.WithLabelValues("sg-1", "web", "ingress"), 2},
+		{"Egress rules", c.rules.WithLabelValues("sg-1", "web", "egress"), 1},
+		{"Empty group", c.rules.WithLabelValues("sg-2", "old", "ingress"), 0},
+		{"World open SSH", c.worldOpen.WithLabelValues("22"), 1},
+		{"World open MySQL", c.worldOpen.WithLabelValues("3306"), 0},
+		{"Unused groups", c.unused, 1},
+		{"Pending instances", c.instances.WithLabelValues("sg-12345678", "pending"), 1},
+		{"Running instances", c.instances.WithLabelValues("sg-12345678", "running"), 0},
+	}
+	for _, tc := range data {
+		if value := gaugeValue(t, tc.gauge); value != tc.expected {
+			t.Errorf("%s: %v is not %v", tc.name, value, tc.expected)
+		}
+	}
+}
+
+func TestCollectorCollect(t *testing.T) {
+	c := NewCollector(&mockEC2Client{}, nil)
+	ch := make(chan prometheus.Metric, 100)
+	c.Collect(ch)
+	close(ch)
+	if len(ch) == 0 {
+		t.Error("No metrics collected")
+	}
+}
@@ -2,6 +2,7 @@ package capcom
 
 import (
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 )
@@ -67,3 +68,157 @@ func (m *mockEC2Client) RevokeSecurityGroupIngress(
 ) {
 	return &ec2.RevokeSecurityGroupIngressOutput{}, nil
 }
+
+func (m *mockEC2Client) DescribeNetworkInterfaces(
+	in *ec2.DescribeNetworkInterfacesInput,
+) (
+	out *ec2.DescribeNetworkInterfacesOutput,
+	err error,
+) {
+	out = &ec2.DescribeNetworkInterfacesOutput{
+		NetworkInterfaces: []*ec2.NetworkInterface{
+			{
+				NetworkInterfaceId: aws.String("eni-1234"),
+				Groups: []*ec2.GroupIdentifier{
+					{GroupId: aws.String("sg-1234")},
+				},
+			},
+		},
+	}
+	return
+}
+
+func (m *mockEC2Client) AuthorizeSecurityGroupEgress(
+	params *ec2.AuthorizeSecurityGroupEgressInput,
+) (
+	*ec2.AuthorizeSecurityGroupEgressOutput,
+	error,
+) {
+	return &ec2.AuthorizeSecurityGroupEgressOutput{}, nil
+}
+
+func (m *mockEC2Client) RevokeSecurityGroupEgress(
+	params *ec2.RevokeSecurityGroupEgressInput,
+) (
+	*ec2.RevokeSecurityGroupEgressOutput,
+	error,
+) {
+	return &ec2.RevokeSecurityGroupEgressOutput{}, nil
+}
+
+func (m *mockEC2Client) CreateTags(
+	params *ec2.CreateTagsInput,
+) (
+	*ec2.CreateTagsOutput,
+	error,
+) {
+	return &ec2.CreateTagsOutput{}, nil
+}
+
+func (m *mockEC2Client) DeleteSecurityGroup(
+	params *ec2.DeleteSecurityGroupInput,
+) (
+	*ec2.DeleteSecurityGroupOutput,
+	error,
+) {
+	return &ec2.DeleteSecurityGroupOutput{}, nil
+}
+
+func (m *mockEC2Client) CreateSecurityGroupWithContext(
+	ctx aws.Context,
+	params *ec2.CreateSecurityGroupInput,
+	opts ...request.Option,
+) (
+	*ec2.CreateSecurityGroupOutput,
+	error,
+) {
+	return m.CreateSecurityGroup(params)
+}
+
+func (m *mockEC2Client) CreateTagsWithContext(
+	ctx aws.Context,
+	params *ec2.CreateTagsInput,
+	opts ...request.Option,
+) (
+	*ec2.CreateTagsOutput,
+	error,
+) {
+	return m.CreateTags(params)
+}
+
+func (m *mockEC2Client) DescribeSecurityGroupsWithContext(
+	ctx aws.Context,
+	params *ec2.DescribeSecurityGroupsInput,
+	opts ...request.Option,
+) (
+	*ec2.DescribeSecurityGroupsOutput,
+	error,
+) {
+	return m.DescribeSecurityGroups(params)
+}
+
+func (m *mockEC2Client) AuthorizeSecurityGroupIngressWithContext(
+	ctx aws.Context,
+	params *ec2.AuthorizeSecurityGroupIngressInput,
+	opts ...request.Option,
+) (
+	*ec2.AuthorizeSecurityGroupIngressOutput,
+	error,
+) {
+	return m.AuthorizeSecurityGroupIngress(params)
+}
+
+func (m *mockEC2Client) RevokeSecurityGroupIngressWithContext(
+	ctx aws.Context,
+	params *ec2.RevokeSecurityGroupIngressInput,
+	opts ...request.Option,
+) (
+	*ec2.RevokeSecurityGroupIngressOutput,
+	error,
+) {
+	return m.RevokeSecurityGroupIngress(params)
+}
+
+func (m *mockEC2Client) AuthorizeSecurityGroupEgressWithContext(
+	ctx aws.Context,
+	params *ec2.AuthorizeSecurityGroupEgressInput,
+	opts ...request.Option,
+) (
+	*ec2.AuthorizeSecurityGroupEgressOutput,
+	error,
+) {
+	return m.AuthorizeSecurityGroupEgress(params)
+}
+
+func (m *mockEC2Client) RevokeSecurityGroupEgressWithContext(
+	ctx aws.Context,
+	params *ec2.RevokeSecurityGroupEgressInput,
+	opts ...request.Option,
+) (
+	*ec2.RevokeSecurityGroupEgressOutput,
+	error,
+) {
+	return m.RevokeSecurityGroupEgress(params)
+}
+
+func (m *mockEC2Client) DescribeNetworkInterfacesWithContext(
+	ctx aws.Context,
+	params *ec2.DescribeNetworkInterfacesInput,
+	opts ...request.Option,
+) (
+	*ec2.DescribeNetworkInterfacesOutput,
+	error,
+) {
+	return m.DescribeNetworkInterfaces(params)
+}
+
+func (m *mockEC2Client) DeleteSecurityGroupWithContext(
+	ctx aws.Context,
+	params *ec2.DeleteSecurityGroupInput,
+	opts ...request.Option,
+) (
+	*ec2.DeleteSecurityGroupOutput,
+	error,
+) {
+	return m.DeleteSecurityGroup(params)
+}
@@ -0,0 +1,90 @@
+package capcom
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+// DuplicateRuleError is returned when authorizing a rule which already
+// exists in the Security Group.
+type DuplicateRuleError struct {
+	GroupID string
+	Err     error
+}
+
+func (e *DuplicateRuleError) Error() string {
+	return fmt.Sprintf("Rule already exists in %s: %s", e.GroupID, e.Err)
+}
+
+// DuplicateGroupError is returned when creating a Security Group with
+// the name of an existing one.
+type DuplicateGroupError struct {
+	Name string
+	Err  error
+}
+
+func (e *DuplicateGroupError) Error() string {
+	return fmt.Sprintf("Security Group %s already exists: %s", e.Name, e.Err)
+}
+
+// NotFoundError is returned when the Security Group, or the rule being
+// revoked, doesn't exist.
+type NotFoundError struct {
+	ID  string
+	Err error
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s not found: %s", e.ID, e.Err)
+}
+
+// LimitExceededError is returned when an operation would exceed the
+// amount of Security Groups, or rules per group, allowed by AWS.
+type LimitExceededError struct {
+	ID  string
+	Err error
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("Limit exceeded for %s: %s", e.ID, e.Err)
+}
+
+// AWS error codes translated into typed errors.
+const (
+	codeDuplicatePermission = "InvalidPermission.Duplicate"
+	codePermissionNotFound  = "InvalidPermission.NotFound"
+	codeDuplicateGroup      = "InvalidGroup.Duplicate"
+)
+
+// classifyError translates the AWS errors for operations on id into
+// DuplicateRuleError, DuplicateGroupError, NotFoundError or
+// LimitExceededError. Other errors are returned untouched.
+func classifyError(id string, err error) error {
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return err
+	}
+	switch aerr.Code() {
+	case codeDuplicatePermission:
+		return &DuplicateRuleError{GroupID: id, Err: err}
+	case codeDuplicateGroup:
+		return &DuplicateGroupError{Name: id, Err: err}
+	case codePermissionNotFound,
+		"InvalidGroup.NotFound",
+		"InvalidGroupId.NotFound":
+		return &NotFoundError{ID: id, Err: err}
+	case "RulesPerSecurityGroupLimitExceeded",
+		"SecurityGroupLimitExceeded",
+		"SecurityGroupsPerInstanceLimitExceeded",
+		"SecurityGroupsPerInterfaceLimitExceeded":
+		return &LimitExceededError{ID: id, Err: err}
+	}
+	return err
+}
+
+// hasCode returns true if err is an AWS error with code.
+func hasCode(err error, code string) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == code
+}
@@ -0,0 +1,361 @@
+package capcom
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// ExportedGroup is a Security Group, with its rules, as dumped by
+// ExportSecurityGroups.
+type ExportedGroup struct {
+	GroupID     string            `json:"group_id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	VpcID       string            `json:"vpc_id,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Rules       []Rule            `json:"rules"`
+}
+
+// Export is a dump of Security Groups which can be rendered as JSON or
+// Terraform, and applied again with ImportSecurityGroups.
+type Export struct {
+	Groups []ExportedGroup `json:"groups"`
+}
+
+// ExportSecurityGroups dumps all Security Groups matching filter.
+func ExportSecurityGroups(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+	filter GroupFilter,
+) (Export, error) {
+	sglist, err := filterSecurityGroups(ctx, svc, filter.filters())
+	if err != nil {
+		return Export{}, err
+	}
+	return exportSecurityGroups(sglist), nil
+}
+
+func exportSecurityGroups(sglist []*ec2.SecurityGroup) (out Export) {
+	out.Groups = []ExportedGroup{}
+	for _, sg := range sglist {
+		group := ExportedGroup{
+			GroupID:     aws.StringValue(sg.GroupId),
+			Name:        aws.StringValue(sg.GroupName),
+			Description: aws.StringValue(sg.Description),
+			VpcID:       aws.StringValue(sg.VpcId),
+			Rules:       Rules(sg),
+		}
+		if len(sg.Tags) > 0 {
+			group.Tags = make(map[string]string)
+			for _, tag := range sg.Tags {
+				group.Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+			}
+		}
+		if group.Rules == nil {
+			group.Rules = []Rule{}
+		}
+		out.Groups = append(out.Groups, group)
+	}
+	return
+}
+
+// ParseExport reads an Export from its JSON representation.
+func ParseExport(data []byte) (out Export, err error) {
+	err = json.Unmarshal(data, &out)
+	return
+}
+
+// JSON returns the Export in JSON format.
+func (e Export) JSON() (string, error) {
+	out, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+var terraformInvalid = regexp.MustCompile("[^a-zA-Z0-9_]")
+
+// terraformName returns the Terraform resource name for a sgid.
+func terraformName(sgid string) string {
+	return terraformInvalid.ReplaceAllString(sgid, "_")
+}
+
+// Terraform returns the Export as aws_security_group and
+// aws_security_group_rule resources. References between exported
+// groups are expressed as Terraform references, and the commands to
+// import the existing groups into the Terraform state are included as
+// comments.
+func (e Export) Terraform() string {
+	exported := make(map[string]bool)
+	for _, group := range e.Groups {
+		exported[group.GroupID] = true
+	}
+	var b strings.Builder
+	for _, group := range e.Groups {
+		name := terraformName(group.GroupID)
+		fmt.Fprintf(
+			&b,
+			"# terraform import aws_security_group.%s %s\n",
+			name,
+			group.GroupID,
+		)
+		fmt.Fprintf(&b, "resource \"aws_security_group\" \"%s\" {\n", name)
+		fmt.Fprintf(&b, "  name        = %s\n", strconv.Quote(group.Name))
+		fmt.Fprintf(&b, "  description = %s\n", strconv.Quote(group.Description))
+		if group.VpcID != "" {
+			fmt.Fprintf(&b, "  vpc_id      = %s\n", strconv.Quote(group.VpcID))
+		}
+		if len(group.Tags) > 0 {
+			keys := make([]string, 0, len(group.Tags))
+			for key := range group.Tags {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			b.WriteString("\n  tags {\n")
+			for _, key := range keys {
+				fmt.Fprintf(
+					&b,
+					"    %s = %s\n",
+					strconv.Quote(key),
+					strconv.Quote(group.Tags[key]),
+				)
+			}
+			b.WriteString("  }\n")
+		}
+		b.WriteString("}\n")
+		for i, rule := range group.Rules {
+			b.WriteString("\n")
+			writeTerraformRule(&b, fmt.Sprintf("%s_%d", name, i), rule, exported)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func writeTerraformRule(
+	b *strings.Builder,
+	name string,
+	rule Rule,
+	exported map[string]bool,
+) {
+	direction := "ingress"
+	if rule.Egress {
+		direction = "egress"
+	}
+	fmt.Fprintf(b, "resource \"aws_security_group_rule\" \"%s\" {\n", name)
+	fmt.Fprintf(b, "  type              = %q\n", direction)
+	fmt.Fprintf(
+		b,
+		"  security_group_id = \"${aws_security_group.%s.id}\"\n",
+		terraformName(rule.GroupID),
+	)
+	fmt.Fprintf(b, "  protocol          = %q\n", rule.Protocol)
+	fmt.Fprintf(b, "  from_port         = %d\n", rule.FromPort)
+	fmt.Fprintf(b, "  to_port           = %d\n", rule.ToPort)
+	switch {
+	case strings.HasPrefix(rule.Peer, "sg-") && exported[rule.Peer]:
+		fmt.Fprintf(
+			b,
+			"  source_security_group_id = \"${aws_security_group.%s.id}\"\n",
+			terraformName(rule.Peer),
+		)
+	case strings.HasPrefix(rule.Peer, "sg-"):
+		fmt.Fprintf(b, "  source_security_group_id = %q\n", rule.Peer)
+	case strings.HasPrefix(rule.Peer, "pl-"):
+		fmt.Fprintf(b, "  prefix_list_ids   = [%q]\n", rule.Peer)
+	case strings.Contains(rule.Peer, ":"):
+		fmt.Fprintf(b, "  ipv6_cidr_blocks  = [%q]\n", rule.Peer)
+	default:
+		fmt.Fprintf(b, "  cidr_blocks       = [%q]\n", rule.Peer)
+	}
+	if rule.Description != "" {
+		fmt.Fprintf(b, "  description       = %s\n", strconv.Quote(rule.Description))
+	}
+	b.WriteString("}\n")
+}
+
+// ImportOptions defines where an Export is applied. VpcID, if set,
+// replaces the VPC of all the exported groups, and is required for
+// groups exported without one.
+type ImportOptions struct {
+	VpcID string
+}
+
+// ImportResult reports the sgids the exported groups were applied to,
+// and the rules authorized on them.
+type ImportResult struct {
+	Mapping map[string]string
+	Added   []Rule
+}
+
+// ImportSecurityGroups applies an Export. Groups are matched by name
+// in their VPC, and created if missing. Groups without a VPC to import
+// them to are refused, rather than matched by name in any VPC. Rules not present in the matching
+// group are authorized, while rules only present in the group are left
+// untouched, so applying the same Export twice doesn't change anything.
+// References between exported groups point to the matching groups.
+func ImportSecurityGroups(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+	export Export,
+	opts ImportOptions,
+) (
+	out ImportResult,
+	err error,
+) {
+	out.Mapping = make(map[string]string)
+	existing := make(map[string][]Rule)
+	created := make(map[string]bool)
+	for _, group := range export.Groups {
+		vpcid := group.VpcID
+		if opts.VpcID != "" {
+			vpcid = opts.VpcID
+		}
+		if vpcid == "" {
+			err = fmt.Errorf("%s has no VPC to import it to", group.GroupID)
+			return
+		}
+		var sglist []*ec2.SecurityGroup
+		sglist, err = filterSecurityGroups(
+			ctx,
+			svc,
+			GroupFilter{VpcID: vpcid, Name: group.Name}.filters(),
+		)
+		if err != nil {
+			return
+		}
+		if len(sglist) > 0 {
+			sgid := aws.StringValue(sglist[0].GroupId)
+			out.Mapping[group.GroupID] = sgid
+			existing[sgid] = Rules(sglist[0])
+			continue
+		}
+		var sgid string
+		sgid, err = createClone(
+			ctx,
+			svc,
+			&ec2.SecurityGroup{
+				Description: aws.String(group.Description),
+				Tags:        buildTags(group.Tags),
+			},
+			group.Name,
+			vpcid,
+		)
+		if err != nil {
+			return
+		}
+		log.Printf("Created %s for %s\n", sgid, group.GroupID)
+		out.Mapping[group.GroupID] = sgid
+		created[sgid] = vpcid != ""
+	}
+	for _, group := range export.Groups {
+		sgid := out.Mapping[group.GroupID]
+		if created[sgid] {
+			// New VPC groups get a default egress rule which must go
+			// so egress matches the export.
+			if _, err = svc.RevokeSecurityGroupEgressWithContext(
+				ctx,
+				&ec2.RevokeSecurityGroupEgressInput{
+					GroupId:       aws.String(sgid),
+					IpPermissions: []*ec2.IpPermission{defaultEgress()},
+				},
+			); err != nil {
+				return
+			}
+		}
+		for _, rule := range group.Rules {
+			rule.GroupID = sgid
+			if peer, ok := out.Mapping[rule.Peer]; ok {
+				rule.Peer = peer
+			}
+			if hasRule(existing[sgid], rule) {
+				continue
+			}
+			if err = authorizeRule(ctx, svc, rule); err != nil {
+				return
+			}
+			out.Added = append(out.Added, rule)
+		}
+	}
+	return
+}
+
+// hasRule returns true if rules contains rule, ignoring descriptions.
+func hasRule(rules []Rule, rule Rule) bool {
+	for _, r := range rules {
+		r.Description = rule.Description
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// permission returns the IpPermission matching a Rule.
+func (r Rule) permission() *ec2.IpPermission {
+	perm := &ec2.IpPermission{IpProtocol: aws.String(r.Protocol)}
+	if r.Protocol != allProtocols {
+		perm.FromPort = aws.Int64(r.FromPort)
+		perm.ToPort = aws.Int64(r.ToPort)
+	}
+	var desc *string
+	if r.Description != "" {
+		desc = aws.String(r.Description)
+	}
+	switch {
+	case strings.HasPrefix(r.Peer, "sg-"):
+		perm.UserIdGroupPairs = []*ec2.UserIdGroupPair{
+			{GroupId: aws.String(r.Peer), Description: desc},
+		}
+	case strings.HasPrefix(r.Peer, "pl-"):
+		perm.PrefixListIds = []*ec2.PrefixListId{
+			{PrefixListId: aws.String(r.Peer), Description: desc},
+		}
+	case strings.Contains(r.Peer, ":"):
+		perm.Ipv6Ranges = []*ec2.Ipv6Range{
+			{CidrIpv6: aws.String(r.Peer), Description: desc},
+		}
+	default:
+		perm.IpRanges = []*ec2.IpRange{
+			{CidrIp: aws.String(r.Peer), Description: desc},
+		}
+	}
+	return perm
+}
+
+// authorizeRule adds rule to its group.
+func authorizeRule(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+	rule Rule,
+) (err error) {
+	perms := []*ec2.IpPermission{rule.permission()}
+	if rule.Egress {
+		_, err = svc.AuthorizeSecurityGroupEgressWithContext(
+			ctx,
+			&ec2.AuthorizeSecurityGroupEgressInput{
+				GroupId:       aws.String(rule.GroupID),
+				IpPermissions: perms,
+			},
+		)
+		return
+	}
+	_, err = svc.AuthorizeSecurityGroupIngressWithContext(
+		ctx,
+		&ec2.AuthorizeSecurityGroupIngressInput{
+			GroupId:       aws.String(rule.GroupID),
+			IpPermissions: perms,
+		},
+	)
+	return
+}
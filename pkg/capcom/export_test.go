@@ -0,0 +1,322 @@
+package capcom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestExportSecurityGroups(t *testing.T) {
+	svc := &mockEC2Client{}
+	export, err := ExportSecurityGroups(aws.BackgroundContext(), svc, GroupFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(export.Groups) != 1 {
+		t.Fatalf("Unexpected export %v", export)
+	}
+	group := export.Groups[0]
+	if group.GroupID != "sg-1234" || len(group.Rules) != 1 {
+		t.Errorf("Unexpected group %v", group)
+	}
+	data, err := export.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseExport([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Groups[0].Rules[0] != group.Rules[0] {
+		t.Errorf("%v is not %v", parsed.Groups[0].Rules[0], group.Rules[0])
+	}
+}
+
+func TestExportTerraform(t *testing.T) {
+	export := exportSecurityGroups([]*ec2.SecurityGroup{
+		{
+			GroupId:     aws.String("sg-1"),
+			GroupName:   aws.String("web"),
+			Description: aws.String("Web servers"),
+			VpcId:       aws.String("vpc-1"),
+			Tags: []*ec2.Tag{
+				{Key: aws.String("team"), Value: aws.String("ops")},
+			},
+			IpPermissions: []*ec2.IpPermission{
+				{
+					IpProtocol: aws.String("tcp"),
+					FromPort:   aws.Int64(443),
+					ToPort:     aws.Int64(443),
+					IpRanges: []*ec2.IpRange{
+						{
+							CidrIp:      aws.String("0.0.0.0/0"),
+							Description: aws.String("HTTPS"),
+						},
+					},
+					UserIdGroupPairs: []*ec2.UserIdGroupPair{
+						{GroupId: aws.String("sg-2")},
+						{GroupId: aws.String("sg-3")},
+					},
+				},
+			},
+		},
+		{
+			GroupId:     aws.String("sg-2"),
+			GroupName:   aws.String("lb"),
+			Description: aws.String("Load balancers"),
+			VpcId:       aws.String("vpc-1"),
+		},
+	})
+	out := export.Terraform()
+	expected := []string{
+		"# terraform import aws_security_group.sg_1 sg-1\n",
+		"resource \"aws_security_group\" \"sg_1\" {\n",
+		"    \"team\" = \"ops\"\n",
+		"resource \"aws_security_group_rule\" \"sg_1_0\" {\n",
+		"  cidr_blocks       = [\"0.0.0.0/0\"]\n",
+		"  description       = \"HTTPS\"\n",
+		"  source_security_group_id = \"${aws_security_group.sg_2.id}\"\n",
+		"  source_security_group_id = \"sg-3\"\n",
+		"resource \"aws_security_group\" \"sg_2\" {\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(out, line) {
+			t.Errorf("%q not found in:\n%s", line, out)
+		}
+	}
+}
+
+type importEC2Client struct {
+	mockEC2Client
+	existing []*ec2.SecurityGroup
+	ingress  []*ec2.IpPermission
+	egress   []*ec2.IpPermission
+	revoked  int
+}
+
+func (m *importEC2Client) DescribeSecurityGroupsWithContext(
+	ctx aws.Context,
+	in *ec2.DescribeSecurityGroupsInput,
+	opts ...request.Option,
+) (
+	*ec2.DescribeSecurityGroupsOutput,
+	error,
+) {
+	out := &ec2.DescribeSecurityGroupsOutput{}
+	for _, sg := range m.existing {
+		for _, filter := range in.Filters {
+			if aws.StringValue(filter.Name) == "group-name" &&
+				aws.StringValue(filter.Values[0]) == aws.StringValue(sg.GroupName) {
+				out.SecurityGroups = append(out.SecurityGroups, sg)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (m *importEC2Client) AuthorizeSecurityGroupIngressWithContext(
+	ctx aws.Context,
+	in *ec2.AuthorizeSecurityGroupIngressInput,
+	opts ...request.Option,
+) (
+	*ec2.AuthorizeSecurityGroupIngressOutput,
+	error,
+) {
+	m.ingress = append(m.ingress, in.IpPermissions...)
+	return &ec2.AuthorizeSecurityGroupIngressOutput{}, nil
+}
+
+func (m *importEC2Client) AuthorizeSecurityGroupEgressWithContext(
+	ctx aws.Context,
+	in *ec2.AuthorizeSecurityGroupEgressInput,
+	opts ...request.Option,
+) (
+	*ec2.AuthorizeSecurityGroupEgressOutput,
+	error,
+) {
+	m.egress = append(m.egress, in.IpPermissions...)
+	return &ec2.AuthorizeSecurityGroupEgressOutput{}, nil
+}
+
+func (m *importEC2Client) RevokeSecurityGroupEgressWithContext(
+	ctx aws.Context,
+	in *ec2.RevokeSecurityGroupEgressInput,
+	opts ...request.Option,
+) (
+	*ec2.RevokeSecurityGroupEgressOutput,
+	error,
+) {
+	m.revoked++
+	return &ec2.RevokeSecurityGroupEgressOutput{}, nil
+}
+
+func TestImportSecurityGroups(t *testing.T) {
+	svc := &importEC2Client{
+		existing: []*ec2.SecurityGroup{
+			{
+				GroupId:   aws.String("sg-20"),
+				GroupName: aws.String("app"),
+				IpPermissions: []*ec2.IpPermission{
+					{
+						IpProtocol: aws.String("tcp"),
+						FromPort:   aws.Int64(22),
+						ToPort:     aws.Int64(22),
+						IpRanges: []*ec2.IpRange{
+							{CidrIp: aws.String("10.0.0.0/8")},
+						},
+					},
+				},
+			},
+		},
+	}
+	export := Export{
+		Groups: []ExportedGroup{
+			{
+				GroupID:     "sg-1",
+				Name:        "db",
+				Description: "Databases",
+				VpcID:       "vpc-1",
+				Rules: []Rule{
+					{
+						GroupID:  "sg-1",
+						Protocol: "tcp",
+						FromPort: 5432,
+						ToPort:   5432,
+						Peer:     "sg-2",
+					},
+					{
+						GroupID:  "sg-1",
+						Egress:   true,
+						Protocol: "-1",
+						Peer:     "10.0.0.0/8",
+					},
+				},
+			},
+			{
+				GroupID: "sg-2",
+				Name:    "app",
+				VpcID:   "vpc-1",
+				Rules: []Rule{
+					{
+						GroupID:     "sg-2",
+						Protocol:    "tcp",
+						FromPort:    22,
+						ToPort:      22,
+						Peer:        "10.0.0.0/8",
+						Description: "SSH",
+					},
+				},
+			},
+		},
+	}
+	out, err := ImportSecurityGroups(aws.BackgroundContext(), svc, export, ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Mapping["sg-1"] != "sg-12345678" || out.Mapping["sg-2"] != "sg-20" {
+		t.Errorf("Unexpected mapping %v", out.Mapping)
+	}
+	if len(out.Added) != 2 {
+		t.Errorf("Expected 2 added rules, found %v", out.Added)
+	}
+	if len(svc.ingress) != 1 ||
+		aws.StringValue(svc.ingress[0].UserIdGroupPairs[0].GroupId) != "sg-20" {
+		t.Errorf("Unexpected ingress permissions %v", svc.ingress)
+	}
+	if len(svc.egress) != 1 || svc.egress[0].FromPort != nil {
+		t.Errorf("Unexpected egress permissions %v", svc.egress)
+	}
+	if svc.revoked != 1 {
+		t.Errorf("Expected default egress to be revoked once, found %d", svc.revoked)
+	}
+}
+
+func TestImportSecurityGroupsWithoutVPC(t *testing.T) {
+	svc := &importEC2Client{
+		existing: []*ec2.SecurityGroup{
+			{
+				GroupId:   aws.String("sg-20"),
+				GroupName: aws.String("app"),
+				VpcId:     aws.String("vpc-2"),
+			},
+		},
+	}
+	export := Export{
+		Groups: []ExportedGroup{
+			{
+				GroupID: "sg-2",
+				Name:    "app",
+				Rules: []Rule{
+					{
+						GroupID:  "sg-2",
+						Protocol: "tcp",
+						FromPort: 22,
+						ToPort:   22,
+						Peer:     "10.0.0.0/8",
+					},
+				},
+			},
+		},
+	}
+	_, err := ImportSecurityGroups(aws.BackgroundContext(), svc, export, ImportOptions{})
+	if err == nil || err.Error() != "sg-2 has no VPC to import it to" {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if len(svc.ingress) != 0 {
+		t.Errorf("Unexpected ingress permissions %v", svc.ingress)
+	}
+	out, err := ImportSecurityGroups(
+		aws.BackgroundContext(),
+		svc,
+		export,
+		ImportOptions{VpcID: "vpc-2"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Mapping["sg-2"] != "sg-20" {
+		t.Errorf("Unexpected mapping %v", out.Mapping)
+	}
+}
+
+func TestRulePermission(t *testing.T) {
+	data := []struct {
+		rule  Rule
+		check func(*ec2.IpPermission) bool
+	}{
+		{
+			rule: Rule{Protocol: "tcp", FromPort: 80, ToPort: 80, Peer: "1.2.3.4/32"},
+			check: func(p *ec2.IpPermission) bool {
+				return len(p.IpRanges) == 1 && aws.Int64Value(p.ToPort) == 80
+			},
+		},
+		{
+			rule: Rule{Protocol: "tcp", FromPort: 80, ToPort: 80, Peer: "::/0"},
+			check: func(p *ec2.IpPermission) bool {
+				return len(p.Ipv6Ranges) == 1
+			},
+		},
+		{
+			rule: Rule{Protocol: "tcp", FromPort: 80, ToPort: 80, Peer: "pl-1234"},
+			check: func(p *ec2.IpPermission) bool {
+				return len(p.PrefixListIds) == 1
+			},
+		},
+		{
+			rule: Rule{Protocol: "icmp", FromPort: 8, ToPort: -1, Peer: "sg-1", Description: "Ping"},
+			check: func(p *ec2.IpPermission) bool {
+				return len(p.UserIdGroupPairs) == 1 &&
+					aws.StringValue(p.UserIdGroupPairs[0].Description) == "Ping" &&
+					aws.Int64Value(p.FromPort) == 8
+			},
+		},
+	}
+	for _, tc := range data {
+		if !tc.check(tc.rule.permission()) {
+			t.Errorf("Unexpected permission %v for %s", tc.rule.permission(), tc.rule)
+		}
+	}
+}
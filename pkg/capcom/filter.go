@@ -0,0 +1,88 @@
+package capcom
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// GroupFilter restricts the Security Groups to work on. Name accepts
+// the same wildcards as the EC2 API filters.
+type GroupFilter struct {
+	VpcID string
+	Name  string
+	Tags  map[string]string
+}
+
+// filters returns the EC2 API filters matching the GroupFilter.
+func (o GroupFilter) filters() (out []*ec2.Filter) {
+	if o.VpcID != "" {
+		out = append(out, &ec2.Filter{
+			Name:   aws.String("vpc-id"),
+			Values: []*string{aws.String(o.VpcID)},
+		})
+	}
+	if o.Name != "" {
+		out = append(out, &ec2.Filter{
+			Name:   aws.String("group-name"),
+			Values: []*string{aws.String(o.Name)},
+		})
+	}
+	keys := make([]string, 0, len(o.Tags))
+	for key := range o.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		out = append(out, &ec2.Filter{
+			Name:   aws.String("tag:" + key),
+			Values: []*string{aws.String(o.Tags[key])},
+		})
+	}
+	return
+}
+
+// buildTags converts a map into a list of EC2 tags, sorted by key.
+func buildTags(tags map[string]string) (out []*ec2.Tag) {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		out = append(out, &ec2.Tag{
+			Key:   aws.String(key),
+			Value: aws.String(tags[key]),
+		})
+	}
+	return
+}
+
+// userTags returns tags without the ones reserved by AWS, prefixed by
+// aws:, which can't be set by users.
+func userTags(tags []*ec2.Tag) (out []*ec2.Tag) {
+	for _, tag := range tags {
+		if !strings.HasPrefix(aws.StringValue(tag.Key), "aws:") {
+			out = append(out, tag)
+		}
+	}
+	return
+}
+
+// formatTags returns tags as a comma separated list of key=value pairs,
+// sorted by key.
+func formatTags(tags []*ec2.Tag) string {
+	out := make([]string, len(tags))
+	for i, tag := range tags {
+		out[i] = fmt.Sprintf(
+			"%s=%s",
+			aws.StringValue(tag.Key),
+			aws.StringValue(tag.Value),
+		)
+	}
+	sort.Strings(out)
+	return strings.Join(out, ",")
+}
@@ -0,0 +1,38 @@
+package capcom
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestGroupFilterFilters(t *testing.T) {
+	opts := GroupFilter{
+		VpcID: "vpc-1",
+		Name:  "web-*",
+		Tags:  map[string]string{"env": "prod", "app": "web"},
+	}
+	expected := []string{"vpc-id", "group-name", "tag:app", "tag:env"}
+	filters := opts.filters()
+	if len(filters) != len(expected) {
+		t.Fatalf("Unexpected filters %v", filters)
+	}
+	for i, filter := range filters {
+		if *filter.Name != expected[i] {
+			t.Errorf("Unexpected filter %s != %s", *filter.Name, expected[i])
+		}
+	}
+}
+
+func TestFormatTags(t *testing.T) {
+	tags := buildTags(map[string]string{"env": "prod", "app": "web"})
+	if len(tags) != 2 || *tags[0].Key != "app" {
+		t.Errorf("Unexpected tags %v", tags)
+	}
+	tags = append(tags, &ec2.Tag{Key: aws.String("a"), Value: aws.String("")})
+	expected := "a=,app=web,env=prod"
+	if out := formatTags(tags); out != expected {
+		t.Errorf("%s is not %s", out, expected)
+	}
+}
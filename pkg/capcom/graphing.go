@@ -3,12 +3,15 @@ package capcom
 import (
 	"fmt"
 	"log"
-	"strconv"
+	"sort"
+	"strings"
 
 	"github.com/awalterschulze/gographviz"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+
+	"github.com/poka-yoke/spaceflight/internal/diagram"
 )
 
 type sGInstanceState map[string]map[string]int
@@ -32,157 +35,315 @@ func (s sGInstanceState) has(key string) bool {
 	return false
 }
 
-func getInstances(svc ec2iface.EC2API) *ec2.DescribeInstancesOutput {
-	// TODO: Check for need of pagination and handle it
-	resp, err := svc.DescribeInstances(
-		&ec2.DescribeInstancesInput{
-			MaxResults: aws.Int64(1000),
-		},
-	)
-	if err != nil {
-		log.Panic(err.Error())
+// getInstances retrieves all instances in the account, following
+// pagination
+func getInstances(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+) (*ec2.DescribeInstancesOutput, error) {
+	out := &ec2.DescribeInstancesOutput{}
+	params := &ec2.DescribeInstancesInput{
+		MaxResults: aws.Int64(1000),
+	}
+	for {
+		resp, err := svc.DescribeInstancesWithContext(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		out.Reservations = append(out.Reservations, resp.Reservations...)
+		if resp.NextToken == nil {
+			return out, nil
+		}
+		params.NextToken = resp.NextToken
+	}
+}
+
+func newInstanceState() map[string]int {
+	return map[string]int{
+		"pending":       0,
+		"running":       0,
+		"shutting-down": 0,
+		"terminated":    0,
+		"stopping":      0,
+		"stopped":       0,
 	}
-	return resp
 }
 
 func getInstancesStates(instances []*ec2.Reservation) sGInstanceState {
 	iState := make(sGInstanceState)
 	for _, res := range instances {
-		state := map[string]int{
-			"pending":       0,
-			"running":       0,
-			"shutting-down": 0,
-			"terminated":    0,
-			"stopping":      0,
-			"stopped":       0,
-		}
 		for _, instance := range res.Instances {
-			state[*instance.State.Name]++
-		}
-		for _, group := range res.Groups {
-			iState[*group.GroupId] = state
+			// VPC instances only list their groups on the instance
+			groups := instance.SecurityGroups
+			if len(groups) == 0 {
+				groups = res.Groups
+			}
+			for _, group := range groups {
+				if iState[*group.GroupId] == nil {
+					iState[*group.GroupId] = newInstanceState()
+				}
+				iState[*group.GroupId][*instance.State.Name]++
+			}
 		}
 	}
 	return iState
 }
 
-func nodeAttrs(sg *ec2.SecurityGroup) (attrs map[string]string) {
-	attrs = make(map[string]string)
-	attrs["label"] = fmt.Sprintf("{{%s|}|%s}", *sg.GroupId, *sg.GroupName)
-	return
+// Kinds of nodes in a Graph.
+const (
+	NodeSecurityGroup = "sg"
+	NodeCIDR          = "cidr"
+	NodePrefixList    = "prefix-list"
+)
+
+// GraphNode is a Security Group, CIDR or prefix list in a Graph.
+type GraphNode struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Kind  string `json:"kind"`
+	VpcID string `json:"vpc_id,omitempty"`
+	Color string `json:"color,omitempty"`
 }
 
-func registerNodes(
-	sglist []*ec2.SecurityGroup,
-	graph *gographviz.Escape,
-	nodesPresence sGInstanceState,
-) {
-	for _, sg := range sglist {
-		log.Printf(
-			"Adding node for %s (%s)\n",
-			*sg.GroupName,
-			*sg.GroupId,
-		)
-		attrs := nodeAttrs(sg)
-		switch {
-		case nodesPresence[*sg.GroupId]["running"] > 0:
-			attrs["color"] = "green"
-		case nodesPresence[*sg.GroupId]["running"] == 0 &&
-			nodesPresence[*sg.GroupId]["stopped"] > 0:
-			attrs["color"] = "yellow"
-		case nodesPresence[*sg.GroupId]["running"] == 0 &&
-			nodesPresence[*sg.GroupId]["stopped"] == 0:
-			attrs["color"] = "red"
-		}
-		if err := graph.AddNode("G", *sg.GroupId, attrs); err != nil {
-			log.Println(err)
-		}
-		if nodesPresence[*sg.GroupId] == nil {
-			nodesPresence[*sg.GroupId] = nil
-		}
+// GraphEdge links a Security Group to a peer referenced in one of its
+// rules.
+type GraphEdge struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Label  string `json:"label"`
+	Egress bool   `json:"egress"`
+}
+
+// Graph represents the relations between Security Groups and the peers
+// their rules allow.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+func groupColor(state map[string]int) string {
+	switch {
+	case state["running"] > 0:
+		return "green"
+	case state["stopped"] > 0:
+		return "yellow"
 	}
+	return "red"
 }
 
-func edgeAttrs(perm *ec2.IpPermission) (attrs map[string]string) {
-	var val string
-	if perm.FromPort != nil && perm.ToPort != nil {
-		fromport := strconv.FormatInt(*perm.FromPort, 10)
-		toport := strconv.FormatInt(*perm.ToPort, 10)
-		if *perm.FromPort == *perm.ToPort {
-			val = fmt.Sprintf(
-				"%s: %s",
-				*perm.IpProtocol,
-				fromport,
-			)
-		} else {
-			val = fmt.Sprintf(
-				"%s: %s - %s",
-				*perm.IpProtocol,
-				fromport,
-				toport,
-			)
-		}
-		attrs = make(map[string]string)
-		attrs["label"] = val
+func ruleLabel(rule Rule) string {
+	switch {
+	case rule.Protocol == allProtocols:
+		return "all"
+	case rule.FromPort == rule.ToPort:
+		return fmt.Sprintf("%s: %d", rule.Protocol, rule.FromPort)
+	}
+	return fmt.Sprintf(
+		"%s: %d - %d",
+		rule.Protocol,
+		rule.FromPort,
+		rule.ToPort,
+	)
+}
+
+func peerKind(peer string) string {
+	switch {
+	case strings.HasPrefix(peer, "sg-"):
+		return NodeSecurityGroup
+	case strings.HasPrefix(peer, "pl-"):
+		return NodePrefixList
 	}
-	return attrs
+	return NodeCIDR
 }
 
-func registerEdges(
-	sglist []*ec2.SecurityGroup,
-	graph *gographviz.Escape,
-	nodesPresence sGInstanceState,
-) {
+// buildGraph creates a Graph from a list of Security Groups and the
+// states of the instances in them.
+func buildGraph(sglist []*ec2.SecurityGroup, states sGInstanceState) *Graph {
+	g := &Graph{}
+	seen := make(map[string]bool)
+	for _, sg := range sglist {
+		sgid := aws.StringValue(sg.GroupId)
+		log.Printf(
+			"Adding node for %s (%s)\n",
+			aws.StringValue(sg.GroupName),
+			sgid,
+		)
+		g.Nodes = append(g.Nodes, GraphNode{
+			ID:    sgid,
+			Label: aws.StringValue(sg.GroupName),
+			Kind:  NodeSecurityGroup,
+			VpcID: aws.StringValue(sg.VpcId),
+			Color: groupColor(states[sgid]),
+		})
+		seen[sgid] = true
+	}
 	for _, sg := range sglist {
 		log.Printf(
 			"Processing entries for %s (%s)\n",
-			*sg.GroupName,
-			*sg.GroupId,
+			aws.StringValue(sg.GroupName),
+			aws.StringValue(sg.GroupId),
 		)
-		for _, perm := range sg.IpPermissions {
-			for _, pair := range perm.UserIdGroupPairs {
-				if nodesPresence.has(*pair.GroupId) {
-					groupName := ""
-					if pair.GroupName != nil {
-						groupName = *pair.GroupName
-					}
-					log.Printf(
-						"Adding Edge for %s (%s) to %s (%s)\n",
-						*sg.GroupName,
-						*sg.GroupId,
-						groupName,
-						*pair.GroupId,
-					)
-					if err := graph.AddEdge(
-						*sg.GroupId,
-						*pair.GroupId,
-						true,
-						edgeAttrs(perm),
-					); err != nil {
-						log.Println(err)
-					}
-				}
+		for _, rule := range Rules(sg) {
+			if !seen[rule.Peer] {
+				// Groups outside the graph are shown without details
+				g.Nodes = append(g.Nodes, GraphNode{
+					ID:    rule.Peer,
+					Label: rule.Peer,
+					Kind:  peerKind(rule.Peer),
+				})
+				seen[rule.Peer] = true
 			}
+			g.Edges = append(g.Edges, GraphEdge{
+				From:   rule.GroupID,
+				To:     rule.Peer,
+				Label:  ruleLabel(rule),
+				Egress: rule.Egress,
+			})
 		}
 	}
+	return g
 }
 
-// GraphSGRelations returns a string containing a graph representation in DOT
-// format of the relations between Security Groups in the service.
-func GraphSGRelations(svc ec2iface.EC2API) string {
-	sglist := getSecurityGroups(svc).SecurityGroups
+// BuildGraph returns the Graph of relations between the Security Groups
+// matching filter and their peers.
+func BuildGraph(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+	filter GroupFilter,
+) (*Graph, error) {
+	sglist, err := filterSecurityGroups(ctx, svc, filter.filters())
+	if err != nil {
+		return nil, err
+	}
+	instances, err := getInstances(ctx, svc)
+	if err != nil {
+		return nil, err
+	}
+	log.Println("Created graph")
+	return buildGraph(sglist, getInstancesStates(instances.Reservations)), nil
+}
 
-	g := gographviz.NewEscape()
-	if err := g.SetName("G"); err != nil {
+// vpcs returns the sorted list of VPCs with nodes in the graph.
+func (g *Graph) vpcs() (out []string) {
+	seen := make(map[string]bool)
+	for _, node := range g.Nodes {
+		if node.VpcID != "" && !seen[node.VpcID] {
+			seen[node.VpcID] = true
+			out = append(out, node.VpcID)
+		}
+	}
+	sort.Strings(out)
+	return
+}
+
+// DOT returns the graph in DOT format, with a cluster per VPC.
+func (g *Graph) DOT() string {
+	graph := gographviz.NewEscape()
+	if err := graph.SetName("G"); err != nil {
 		log.Println(err)
 	}
-	if err := g.SetDir(true); err != nil {
+	if err := graph.SetDir(true); err != nil {
 		log.Println(err)
 	}
-	log.Println("Created graph")
+	for _, vpc := range g.vpcs() {
+		if err := graph.AddSubGraph(
+			"G",
+			"cluster_"+vpc,
+			map[string]string{"label": vpc},
+		); err != nil {
+			log.Println(err)
+		}
+	}
+	for _, node := range g.Nodes {
+		parent := "G"
+		if node.VpcID != "" {
+			parent = "cluster_" + node.VpcID
+		}
+		attrs := map[string]string{"label": node.Label}
+		switch node.Kind {
+		case NodeSecurityGroup:
+			attrs["shape"] = "record"
+			name := node.Label
+			if name == node.ID {
+				name = ""
+			}
+			attrs["label"] = fmt.Sprintf("{{%s|}|%s}", node.ID, name)
+		default:
+			attrs["shape"] = "box"
+		}
+		if node.Color != "" {
+			attrs["color"] = node.Color
+		}
+		if err := graph.AddNode(parent, node.ID, attrs); err != nil {
+			log.Println(err)
+		}
+	}
+	for _, edge := range g.Edges {
+		attrs := map[string]string{"label": edge.Label}
+		if edge.Egress {
+			attrs["style"] = "dashed"
+		}
+		if err := graph.AddEdge(edge.From, edge.To, true, attrs); err != nil {
+			log.Println(err)
+		}
+	}
+	return graph.String()
+}
+
+// mermaidLabel returns the label of node in Mermaid flowcharts, with
+// the name of Security Groups under their sgid.
+func mermaidLabel(node GraphNode) string {
+	if node.Kind == NodeSecurityGroup && node.Label != node.ID {
+		return fmt.Sprintf("%s<br/>%s", node.ID, node.Label)
+	}
+	return node.Label
+}
+
+// Mermaid returns the graph as a Mermaid flowchart, with a subgraph per
+// VPC.
+func (g *Graph) Mermaid() string {
+	m := diagram.NewMermaid()
+	for _, vpc := range g.vpcs() {
+		m.Subgraph(vpc)
+		for _, node := range g.Nodes {
+			if node.VpcID == vpc {
+				m.Node(node.ID, mermaidLabel(node), diagram.Box)
+			}
+		}
+		m.End()
+	}
+	for _, node := range g.Nodes {
+		if node.VpcID == "" {
+			m.Node(node.ID, mermaidLabel(node), diagram.Box)
+		}
+	}
+	for _, edge := range g.Edges {
+		arrow := diagram.Arrow
+		if edge.Egress {
+			arrow = diagram.Dotted
+		}
+		m.Edge(edge.From, edge.To, edge.Label, arrow)
+	}
+	for _, node := range g.Nodes {
+		if node.Color != "" {
+			m.Style(node.ID, "stroke:"+node.Color)
+		}
+	}
+	return m.String()
+}
 
-	nodesPresence := getInstancesStates(getInstances(svc).Reservations)
-	registerNodes(sglist, g, nodesPresence)
-	registerEdges(sglist, g, nodesPresence)
-	return g.String()
+// JSON returns the graph as a JSON document with a list of nodes and a
+// list of edges.
+func (g *Graph) JSON() (string, error) {
+	return diagram.JSON(g)
+}
+
+// GraphSGRelations returns a string containing a graph representation in DOT
+// format of the relations between Security Groups in the service.
+func GraphSGRelations(ctx aws.Context, svc ec2iface.EC2API) (string, error) {
+	g, err := BuildGraph(ctx, svc, GroupFilter{})
+	if err != nil {
+		return "", err
+	}
+	return g.DOT(), nil
 }
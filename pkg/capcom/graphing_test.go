@@ -1,9 +1,12 @@
 package capcom
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/ec2"
 )
 
@@ -55,9 +58,23 @@ func (m *mockEC2Client) DescribeInstances(
 	return &describeInstancesOutput, nil
 }
 
+func (m *mockEC2Client) DescribeInstancesWithContext(
+	ctx aws.Context,
+	in *ec2.DescribeInstancesInput,
+	opts ...request.Option,
+) (
+	*ec2.DescribeInstancesOutput,
+	error,
+) {
+	return m.DescribeInstances(in)
+}
+
 func TestGetInstances(t *testing.T) {
 	svc := &mockEC2Client{}
-	res := getInstances(svc)
+	res, err := getInstances(aws.BackgroundContext(), svc)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if *res.Reservations[0].Instances[0].State.Name != "pending" ||
 		*res.Reservations[0].Groups[0].GroupId != "sg-12345678" {
 		t.Error("Should be equal")
@@ -77,3 +94,110 @@ func TestGetInstancesStates(t *testing.T) {
 		t.Error("Expected key missing")
 	}
 }
+
+var graphGroups = []*ec2.SecurityGroup{
+	{
+		GroupId:   aws.String("sg-12345678"),
+		GroupName: aws.String("web"),
+		VpcId:     aws.String("vpc-1"),
+		IpPermissions: []*ec2.IpPermission{
+			{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(443),
+				ToPort:     aws.Int64(443),
+				IpRanges: []*ec2.IpRange{
+					{CidrIp: aws.String("0.0.0.0/0")},
+				},
+			},
+		},
+		IpPermissionsEgress: []*ec2.IpPermission{
+			{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(5432),
+				ToPort:     aws.Int64(5432),
+				UserIdGroupPairs: []*ec2.UserIdGroupPair{
+					{GroupId: aws.String("sg-87654321")},
+				},
+			},
+		},
+	},
+	{
+		GroupId:   aws.String("sg-87654321"),
+		GroupName: aws.String("db"),
+		VpcId:     aws.String("vpc-1"),
+		IpPermissions: []*ec2.IpPermission{
+			{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(5432),
+				ToPort:     aws.Int64(5432),
+				UserIdGroupPairs: []*ec2.UserIdGroupPair{
+					{GroupId: aws.String("sg-12345678")},
+					{GroupId: aws.String("sg-00000000")},
+				},
+				PrefixListIds: []*ec2.PrefixListId{
+					{PrefixListId: aws.String("pl-1234")},
+				},
+			},
+		},
+	},
+}
+
+func TestBuildGraph(t *testing.T) {
+	g := buildGraph(
+		graphGroups,
+		getInstancesStates(describeInstancesOutput.Reservations),
+	)
+	kinds := map[string]int{}
+	for _, node := range g.Nodes {
+		kinds[node.Kind]++
+	}
+	if kinds[NodeSecurityGroup] != 3 ||
+		kinds[NodeCIDR] != 1 ||
+		kinds[NodePrefixList] != 1 {
+		t.Errorf("Unexpected nodes %v", g.Nodes)
+	}
+	if len(g.Edges) != 5 {
+		t.Errorf("Unexpected edges %v", g.Edges)
+	}
+	egress := 0
+	for _, edge := range g.Edges {
+		if edge.Egress {
+			egress++
+		}
+	}
+	if egress != 1 {
+		t.Errorf("Expected 1 egress edge, found %d", egress)
+	}
+	if g.Nodes[0].Color != "red" || g.Nodes[0].VpcID != "vpc-1" {
+		t.Errorf("Unexpected node %v", g.Nodes[0])
+	}
+}
+
+func TestGraphFormats(t *testing.T) {
+	g := buildGraph(graphGroups, sGInstanceState{})
+	dot := g.DOT()
+	if !strings.Contains(dot, "cluster_vpc-1") {
+		t.Errorf("Missing VPC cluster in %s", dot)
+	}
+	mermaid := g.Mermaid()
+	for _, expected := range []string{
+		"subgraph z_vpc_1[\"vpc-1\"]",
+		"n_sg_12345678 -->|\"tcp: 443\"| n_0_0_0_0_0",
+		"n_sg_12345678 -.->|\"tcp: 5432\"| n_sg_87654321",
+	} {
+		if !strings.Contains(mermaid, expected) {
+			t.Errorf("Missing %s in %s", expected, mermaid)
+		}
+	}
+	out, err := g.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed Graph
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Nodes) != len(g.Nodes) || len(parsed.Edges) != len(g.Edges) {
+		t.Error("JSON output doesn't match the graph")
+	}
+}
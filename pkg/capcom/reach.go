@@ -0,0 +1,294 @@
+package capcom
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// Endpoint is one end of a reachability query: the Security Groups it
+// belongs to and the networks its traffic comes from or goes to.
+type Endpoint struct {
+	Name     string
+	Groups   []string
+	Networks []*net.IPNet
+}
+
+// hasGroup returns true if the endpoint belongs to sgid.
+func (e Endpoint) hasGroup(sgid string) bool {
+	for _, group := range e.Groups {
+		if group == sgid {
+			return true
+		}
+	}
+	return false
+}
+
+// inNetwork returns true if all the endpoint networks are contained in
+// cidr. Endpoints without networks are only contained in the whole
+// address space.
+func (e Endpoint) inNetwork(cidr string) bool {
+	if cidr == "0.0.0.0/0" || cidr == "::/0" {
+		return true
+	}
+	if len(e.Networks) == 0 {
+		return false
+	}
+	for _, network := range e.Networks {
+		if !peerContains(cidr, network.String()) {
+			return false
+		}
+	}
+	return true
+}
+
+// allows returns true if rule peer matches the endpoint.
+func (e Endpoint) allows(rule Rule) bool {
+	return e.hasGroup(rule.Peer) || e.inNetwork(rule.Peer)
+}
+
+// ResolveEndpoint builds an Endpoint from a sgid, an instance id, a
+// CIDR or a single IP address.
+func ResolveEndpoint(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+	spec string,
+) (e Endpoint, err error) {
+	e.Name = spec
+	switch {
+	case strings.HasPrefix(spec, "sg-"):
+		e.Groups = []string{spec}
+		e.Networks, err = getGroupNetworks(ctx, svc, spec)
+	case strings.HasPrefix(spec, "i-"):
+		var instance *ec2.Instance
+		instance, err = getInstance(ctx, svc, spec)
+		if err != nil {
+			return
+		}
+		for _, group := range instance.SecurityGroups {
+			e.Groups = append(e.Groups, aws.StringValue(group.GroupId))
+		}
+		e.Networks = instanceNetworks(instance)
+	case isCIDR(spec):
+		_, network, _ := net.ParseCIDR(spec)
+		e.Networks = []*net.IPNet{network}
+	case net.ParseIP(spec) != nil:
+		e.Networks = []*net.IPNet{hostNetwork(net.ParseIP(spec))}
+	default:
+		err = fmt.Errorf(
+			"%s is neither sgid, instance id nor IP address",
+			spec,
+		)
+	}
+	return
+}
+
+// getInstance retrieves a single instance by its id.
+func getInstance(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+	id string,
+) (*ec2.Instance, error) {
+	res, err := svc.DescribeInstancesWithContext(
+		ctx,
+		&ec2.DescribeInstancesInput{
+			InstanceIds: []*string{aws.String(id)},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, reservation := range res.Reservations {
+		for _, instance := range reservation.Instances {
+			if aws.StringValue(instance.InstanceId) == id {
+				return instance, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("Instance %s not found", id)
+}
+
+// getGroupNetworks returns the private addresses of all network
+// interfaces attached to sgid.
+func getGroupNetworks(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+	sgid string,
+) (out []*net.IPNet, err error) {
+	enis, err := describeGroupInterfaces(ctx, svc, sgid)
+	if err != nil {
+		return
+	}
+	for _, eni := range enis {
+		for _, address := range eni.PrivateIpAddresses {
+			ip := net.ParseIP(aws.StringValue(address.PrivateIpAddress))
+			if ip != nil {
+				out = append(out, hostNetwork(ip))
+			}
+		}
+	}
+	return
+}
+
+// instanceNetworks returns the private addresses of all the network
+// interfaces of an instance.
+func instanceNetworks(instance *ec2.Instance) (out []*net.IPNet) {
+	for _, eni := range instance.NetworkInterfaces {
+		for _, address := range eni.PrivateIpAddresses {
+			ip := net.ParseIP(aws.StringValue(address.PrivateIpAddress))
+			if ip != nil {
+				out = append(out, hostNetwork(ip))
+			}
+		}
+	}
+	if len(out) == 0 {
+		ip := net.ParseIP(aws.StringValue(instance.PrivateIpAddress))
+		if ip != nil {
+			out = append(out, hostNetwork(ip))
+		}
+	}
+	return
+}
+
+// hostNetwork returns a network containing only ip.
+func hostNetwork(ip net.IP) *net.IPNet {
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+// ReachResult explains whether traffic between two endpoints is
+// allowed by their Security Groups.
+type ReachResult struct {
+	Allowed bool
+	Ingress *Rule
+	Egress  *Rule
+	Reason  string
+}
+
+// String method for ReachResult gets a String to be printed.
+func (r ReachResult) String() string {
+	if !r.Allowed {
+		return fmt.Sprintf("Denied: %s", r.Reason)
+	}
+	out := fmt.Sprintf("Allowed by ingress rule %s", r.Ingress)
+	if r.Egress != nil {
+		out += fmt.Sprintf(" and egress rule %s", r.Egress)
+	}
+	return out
+}
+
+// CanReach evaluates the ingress rules of the destination groups and
+// the egress rules of the source groups, if any, to decide if traffic
+// on proto and port from source reaches destination.
+func CanReach(
+	sglist []*ec2.SecurityGroup,
+	from Endpoint,
+	to Endpoint,
+	proto string,
+	port int64,
+) (out ReachResult) {
+	if len(to.Groups) == 0 {
+		out.Reason = fmt.Sprintf("%s has no Security Groups", to.Name)
+		return
+	}
+	groups := make(map[string]*ec2.SecurityGroup)
+	for _, sg := range sglist {
+		groups[aws.StringValue(sg.GroupId)] = sg
+	}
+
+	out.Ingress = findRule(groups, to.Groups, false, proto, port, from)
+	if out.Ingress == nil {
+		out.Reason = fmt.Sprintf(
+			"no ingress rule in %s allows %d/%s from %s",
+			strings.Join(to.Groups, ", "),
+			port,
+			proto,
+			from.Name,
+		)
+		return
+	}
+	if len(from.Groups) > 0 {
+		out.Egress = findRule(groups, from.Groups, true, proto, port, to)
+		if out.Egress == nil {
+			out.Reason = fmt.Sprintf(
+				"no egress rule in %s allows %d/%s to %s",
+				strings.Join(from.Groups, ", "),
+				port,
+				proto,
+				to.Name,
+			)
+			return
+		}
+	}
+	out.Allowed = true
+	return
+}
+
+// findRule returns the first rule in the groups sgids, with the
+// specified direction, allowing traffic on proto and port to or from
+// peer.
+func findRule(
+	groups map[string]*ec2.SecurityGroup,
+	sgids []string,
+	egress bool,
+	proto string,
+	port int64,
+	peer Endpoint,
+) *Rule {
+	for _, sgid := range sgids {
+		sg, ok := groups[sgid]
+		if !ok {
+			log.Printf("Security Group %s not found\n", sgid)
+			continue
+		}
+		for _, rule := range Rules(sg) {
+			if rule.Egress == egress &&
+				rule.CoversPort(proto, port) &&
+				peer.allows(rule) {
+				return &rule
+			}
+		}
+	}
+	return nil
+}
+
+// CheckReachability resolves both endpoints and evaluates if traffic on
+// proto and port from source reaches destination.
+func CheckReachability(
+	ctx aws.Context,
+	svc ec2iface.EC2API,
+	source string,
+	destination string,
+	proto string,
+	port int64,
+) (
+	out ReachResult,
+	err error,
+) {
+	from, err := ResolveEndpoint(ctx, svc, source)
+	if err != nil {
+		return
+	}
+	if !strings.HasPrefix(destination, "sg-") &&
+		!strings.HasPrefix(destination, "i-") {
+		err = fmt.Errorf("%s is neither sgid nor instance id", destination)
+		return
+	}
+	to, err := ResolveEndpoint(ctx, svc, destination)
+	if err != nil {
+		return
+	}
+	sglist, err := getSecurityGroups(ctx, svc)
+	if err != nil {
+		return
+	}
+	out = CanReach(sglist.SecurityGroups, from, to, proto, port)
+	return
+}
@@ -0,0 +1,139 @@
+package capcom
+
+import (
+	"net"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestResolveEndpoint(t *testing.T) {
+	data := []struct {
+		spec     string
+		groups   int
+		networks int
+		err      bool
+	}{
+		{spec: "sg-1234", groups: 1, networks: 0},
+		{spec: "10.0.0.0/8", groups: 0, networks: 1},
+		{spec: "10.0.0.1", groups: 0, networks: 1},
+		{spec: "nonsense", err: true},
+	}
+	svc := &mockEC2Client{}
+	for _, tc := range data {
+		e, err := ResolveEndpoint(aws.BackgroundContext(), svc, tc.spec)
+		if (err != nil) != tc.err {
+			t.Errorf("Unexpected error for %s: %v", tc.spec, err)
+		}
+		if len(e.Groups) != tc.groups || len(e.Networks) != tc.networks {
+			t.Errorf("Unexpected endpoint for %s: %v", tc.spec, e)
+		}
+	}
+}
+
+var reachGroups = []*ec2.SecurityGroup{
+	{
+		GroupId: aws.String("sg-app"),
+		IpPermissionsEgress: []*ec2.IpPermission{
+			{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(443),
+				ToPort:     aws.Int64(443),
+				IpRanges: []*ec2.IpRange{
+					{CidrIp: aws.String("0.0.0.0/0")},
+				},
+			},
+			{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(5432),
+				ToPort:     aws.Int64(5432),
+				UserIdGroupPairs: []*ec2.UserIdGroupPair{
+					{GroupId: aws.String("sg-db")},
+				},
+			},
+		},
+	},
+	{
+		GroupId: aws.String("sg-web"),
+	},
+	{
+		GroupId: aws.String("sg-db"),
+		IpPermissions: []*ec2.IpPermission{
+			{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(5432),
+				ToPort:     aws.Int64(5432),
+				UserIdGroupPairs: []*ec2.UserIdGroupPair{
+					{GroupId: aws.String("sg-app")},
+					{GroupId: aws.String("sg-web")},
+				},
+				IpRanges: []*ec2.IpRange{
+					{CidrIp: aws.String("10.1.0.0/16")},
+				},
+			},
+		},
+	},
+}
+
+func TestCanReach(t *testing.T) {
+	_, office, _ := net.ParseCIDR("10.1.2.0/24")
+	_, home, _ := net.ParseCIDR("192.168.1.1/32")
+	db := Endpoint{Name: "sg-db", Groups: []string{"sg-db"}}
+	data := []struct {
+		name    string
+		from    Endpoint
+		port    int64
+		allowed bool
+		egress  bool
+	}{
+		{
+			name:    "Group reference on both sides",
+			from:    Endpoint{Name: "sg-app", Groups: []string{"sg-app"}},
+			port:    5432,
+			allowed: true,
+			egress:  true,
+		},
+		{
+			name:    "Wrong port",
+			from:    Endpoint{Name: "sg-app", Groups: []string{"sg-app"}},
+			port:    22,
+			allowed: false,
+		},
+		{
+			name:    "Ingress but no egress",
+			from:    Endpoint{Name: "sg-web", Groups: []string{"sg-web"}},
+			port:    5432,
+			allowed: false,
+		},
+		{
+			name:    "CIDR contained in rule",
+			from:    Endpoint{Name: "office", Networks: []*net.IPNet{office}},
+			port:    5432,
+			allowed: true,
+		},
+		{
+			name:    "CIDR outside rule",
+			from:    Endpoint{Name: "home", Networks: []*net.IPNet{home}},
+			port:    5432,
+			allowed: false,
+		},
+	}
+	for _, tc := range data {
+		t.Run(
+			tc.name,
+			func(t *testing.T) {
+				out := CanReach(reachGroups, tc.from, db, "tcp", tc.port)
+				if out.Allowed != tc.allowed {
+					t.Errorf("Unexpected result: %s", out)
+				}
+				if tc.allowed && (out.Egress != nil) != tc.egress {
+					t.Errorf("Unexpected egress rule: %s", out)
+				}
+				if !out.Allowed && out.Reason == "" {
+					t.Error("Denied results must have a reason")
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,162 @@
+package capcom
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// Rule is a single Security Group rule, with exactly one peer (CIDR,
+// IPv6 CIDR, sgid or prefix list id) per entry.
+type Rule struct {
+	GroupID     string `json:"group_id"`
+	Egress      bool   `json:"egress"`
+	Protocol    string `json:"protocol"`
+	FromPort    int64  `json:"from_port"`
+	ToPort      int64  `json:"to_port"`
+	Peer        string `json:"peer"`
+	Description string `json:"description,omitempty"`
+}
+
+// String method for Rule gets a String to be printed.
+func (r Rule) String() string {
+	direction := "ingress"
+	if r.Egress {
+		direction = "egress"
+	}
+	ports := fmt.Sprintf("%d-%d", r.FromPort, r.ToPort)
+	if r.FromPort == r.ToPort {
+		ports = fmt.Sprintf("%d", r.FromPort)
+	}
+	return fmt.Sprintf(
+		"%s %s %s/%s %s",
+		r.GroupID,
+		direction,
+		ports,
+		r.Protocol,
+		r.Peer,
+	)
+}
+
+// Rules flattens the ingress and egress permissions of a Security
+// Group into a list of Rule.
+func Rules(sg *ec2.SecurityGroup) (out []Rule) {
+	out = append(out, flattenPermissions(sg, sg.IpPermissions, false)...)
+	out = append(out, flattenPermissions(sg, sg.IpPermissionsEgress, true)...)
+	return
+}
+
+func flattenPermissions(
+	sg *ec2.SecurityGroup,
+	perms []*ec2.IpPermission,
+	egress bool,
+) (out []Rule) {
+	for _, perm := range perms {
+		base := Rule{
+			GroupID:  aws.StringValue(sg.GroupId),
+			Egress:   egress,
+			Protocol: aws.StringValue(perm.IpProtocol),
+			FromPort: aws.Int64Value(perm.FromPort),
+			ToPort:   aws.Int64Value(perm.ToPort),
+		}
+		for _, ipRange := range perm.IpRanges {
+			r := base
+			r.Peer = aws.StringValue(ipRange.CidrIp)
+			r.Description = aws.StringValue(ipRange.Description)
+			out = append(out, r)
+		}
+		for _, ipv6Range := range perm.Ipv6Ranges {
+			r := base
+			r.Peer = aws.StringValue(ipv6Range.CidrIpv6)
+			r.Description = aws.StringValue(ipv6Range.Description)
+			out = append(out, r)
+		}
+		for _, pair := range perm.UserIdGroupPairs {
+			r := base
+			r.Peer = aws.StringValue(pair.GroupId)
+			r.Description = aws.StringValue(pair.Description)
+			out = append(out, r)
+		}
+		for _, prefixList := range perm.PrefixListIds {
+			r := base
+			r.Peer = aws.StringValue(prefixList.PrefixListId)
+			r.Description = aws.StringValue(prefixList.Description)
+			out = append(out, r)
+		}
+	}
+	return
+}
+
+// allProtocols is the protocol value AWS uses for rules matching any
+// protocol and port.
+const allProtocols = "-1"
+
+// normalizeProtocol maps the IANA numbers AWS accepts for the most
+// common protocols to their names.
+func normalizeProtocol(proto string) string {
+	switch proto {
+	case "6":
+		return "tcp"
+	case "17":
+		return "udp"
+	case "1":
+		return "icmp"
+	}
+	return proto
+}
+
+// portBased returns true if the rule protocol has a meaningful port
+// range.
+func (r Rule) portBased() bool {
+	switch normalizeProtocol(r.Protocol) {
+	case "tcp", "udp":
+		return true
+	}
+	return false
+}
+
+// CoversPort returns true if the rule matches traffic on proto and port.
+func (r Rule) CoversPort(proto string, port int64) bool {
+	if r.Protocol == allProtocols {
+		return true
+	}
+	if normalizeProtocol(r.Protocol) != normalizeProtocol(proto) {
+		return false
+	}
+	if !r.portBased() {
+		return true
+	}
+	return r.FromPort <= port && port <= r.ToPort
+}
+
+// PortSpan returns the amount of ports matched by the rule. Rules on
+// protocols without ports have a span of zero.
+func (r Rule) PortSpan() int64 {
+	switch {
+	case r.Protocol == allProtocols:
+		return 65536
+	case r.portBased():
+		return r.ToPort - r.FromPort + 1
+	}
+	return 0
+}
+
+// coversRule returns true if every packet matched by other is also
+// matched by r, peers aside.
+func (r Rule) coversRule(other Rule) bool {
+	if r.Egress != other.Egress {
+		return false
+	}
+	if r.Protocol == allProtocols {
+		return true
+	}
+	if normalizeProtocol(r.Protocol) != normalizeProtocol(other.Protocol) {
+		return false
+	}
+	if !r.portBased() {
+		return r.FromPort == other.FromPort && r.ToPort == other.ToPort ||
+			r.FromPort == -1
+	}
+	return r.FromPort <= other.FromPort && other.ToPort <= r.ToPort
+}
@@ -0,0 +1,94 @@
+package capcom
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestRules(t *testing.T) {
+	sg := &ec2.SecurityGroup{
+		GroupId: aws.String("sg-1"),
+		IpPermissions: []*ec2.IpPermission{
+			{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(22),
+				ToPort:     aws.Int64(22),
+				IpRanges: []*ec2.IpRange{
+					{CidrIp: aws.String("1.2.3.4/32")},
+				},
+				UserIdGroupPairs: []*ec2.UserIdGroupPair{
+					{GroupId: aws.String("sg-2")},
+				},
+			},
+		},
+		IpPermissionsEgress: []*ec2.IpPermission{
+			{
+				IpProtocol: aws.String("-1"),
+				IpRanges: []*ec2.IpRange{
+					{CidrIp: aws.String("0.0.0.0/0")},
+				},
+			},
+		},
+	}
+	expected := []string{
+		"sg-1 ingress 22/tcp 1.2.3.4/32",
+		"sg-1 ingress 22/tcp sg-2",
+		"sg-1 egress 0/-1 0.0.0.0/0",
+	}
+	out := Rules(sg)
+	if len(out) != len(expected) {
+		t.Fatalf("Expected %d rules, found %d", len(expected), len(out))
+	}
+	for i, rule := range out {
+		if rule.String() != expected[i] {
+			t.Errorf("Unexpected rule %s != %s", rule, expected[i])
+		}
+	}
+}
+
+func TestRuleCoversPort(t *testing.T) {
+	data := []struct {
+		rule     Rule
+		proto    string
+		port     int64
+		expected bool
+	}{
+		{
+			rule:     Rule{Protocol: "tcp", FromPort: 20, ToPort: 30},
+			proto:    "tcp",
+			port:     22,
+			expected: true,
+		},
+		{
+			rule:     Rule{Protocol: "tcp", FromPort: 20, ToPort: 30},
+			proto:    "udp",
+			port:     22,
+			expected: false,
+		},
+		{
+			rule:     Rule{Protocol: "6", FromPort: 20, ToPort: 30},
+			proto:    "tcp",
+			port:     31,
+			expected: false,
+		},
+		{
+			rule:     Rule{Protocol: "-1"},
+			proto:    "udp",
+			port:     53,
+			expected: true,
+		},
+	}
+	for _, tc := range data {
+		if out := tc.rule.CoversPort(tc.proto, tc.port); out != tc.expected {
+			t.Errorf(
+				"%v covering %d/%s: expected %v",
+				tc.rule,
+				tc.port,
+				tc.proto,
+				tc.expected,
+			)
+		}
+	}
+}
@@ -7,19 +7,32 @@ import (
 
 // SearchResult defines a result for a rule
 type SearchResult struct {
-	GroupID  string
-	Protocol string
-	Port     int64
-	Source   string
+	Location
+	GroupID     string
+	Protocol    string
+	Port        int64
+	Source      string
+	Description string
+	Attachments []Attachment
 }
 
 // String method for SearchResult gets a String to be printed.
 func (sr SearchResult) String() string {
-	return fmt.Sprintf(
+	out := fmt.Sprintf(
 		"%s %s/%s %s",
 		sr.GroupID,
 		strconv.FormatInt(sr.Port, 10),
 		sr.Protocol,
 		sr.Source,
 	)
+	if sr.Description != "" {
+		out = fmt.Sprintf("%s (%s)", out, sr.Description)
+	}
+	if location := sr.Location.String(); location != "" {
+		out = fmt.Sprintf("%s %s", location, out)
+	}
+	for _, a := range sr.Attachments {
+		out = fmt.Sprintf("%s\n    %s", out, a)
+	}
+	return out
 }
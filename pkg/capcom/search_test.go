@@ -15,3 +15,33 @@ func TestString(t *testing.T) {
 		t.Errorf("%s is not %s", result, expected)
 	}
 }
+
+func TestStringWithLocation(t *testing.T) {
+	sr := SearchResult{
+		Location: Location{Account: "123456789012", Region: "eu-west-1"},
+		GroupID:  "sg-idsgtest",
+		Protocol: "tcp",
+		Port:     22,
+		Source:   "0.0.0.0/0",
+	}
+	expected := "123456789012/eu-west-1 sg-idsgtest 22/tcp 0.0.0.0/0"
+	result := sr.String()
+	if expected != result {
+		t.Errorf("%s is not %s", result, expected)
+	}
+}
+
+func TestStringWithDescription(t *testing.T) {
+	sr := SearchResult{
+		GroupID:     "sg-idsgtest",
+		Protocol:    "tcp",
+		Port:        22,
+		Source:      "0.0.0.0/0",
+		Description: "Bastion",
+	}
+	expected := "sg-idsgtest 22/tcp 0.0.0.0/0 (Bastion)"
+	result := sr.String()
+	if expected != result {
+		t.Errorf("%s is not %s", result, expected)
+	}
+}
@@ -0,0 +1,166 @@
+package capcom
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// DefaultRegion is the region used when none is specified.
+const DefaultRegion = "us-east-1"
+
+// AllRegions expands to every region available to the account.
+const AllRegions = "all"
+
+// Location identifies the account and region a result comes from.
+type Location struct {
+	Account string `json:"account,omitempty"`
+	Region  string `json:"region,omitempty"`
+}
+
+// String method for Location gets a String to be printed.
+func (l Location) String() string {
+	if l.Account == "" && l.Region == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s", l.Account, l.Region)
+}
+
+// Target is an EC2 API client bound to an account and region, with an
+// RDS one to resolve the DB instances using Security Groups.
+type Target struct {
+	Location
+	Svc ec2iface.EC2API
+	RDS rdsiface.RDSAPI
+
+	set credentialSet
+}
+
+// newTarget returns the Target of set on account and region.
+func newTarget(set credentialSet, account string, region string) Target {
+	config := set.config.Copy().WithRegion(region)
+	return Target{
+		Location: Location{Account: account, Region: region},
+		Svc:      ec2.New(set.session, config),
+		RDS:      rds.New(set.session, config),
+		set:      set,
+	}
+}
+
+// WithRegion returns a Target on the same account in another region.
+func (t Target) WithRegion(region string) Target {
+	return newTarget(t.set, t.Account, region)
+}
+
+// TargetOptions selects the regions and accounts to work on. Each
+// profile is an account, unless roles are specified, in which case
+// each role assumed from each profile is an account. An empty profile
+// stands for the default credentials chain.
+type TargetOptions struct {
+	Regions  []string
+	Profiles []string
+	Roles    []string
+}
+
+// credentialSet is a session able to work on a single account.
+type credentialSet struct {
+	session *session.Session
+	config  *aws.Config
+}
+
+// NewTargets creates a Target per account and region selected by opts.
+func NewTargets(opts TargetOptions) (out []Target, err error) {
+	sets, err := newCredentialSets(opts)
+	if err != nil {
+		return
+	}
+	for _, set := range sets {
+		account, err := getAccountID(set)
+		if err != nil {
+			return nil, err
+		}
+		regions, err := expandRegions(
+			ec2.New(set.session, set.config.Copy().WithRegion(DefaultRegion)),
+			opts.Regions,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, region := range regions {
+			out = append(out, newTarget(set, account, region))
+		}
+	}
+	return
+}
+
+func newCredentialSets(opts TargetOptions) (out []credentialSet, err error) {
+	profiles := opts.Profiles
+	if len(profiles) == 0 {
+		profiles = []string{""}
+	}
+	for _, profile := range profiles {
+		sess, err := session.NewSessionWithOptions(session.Options{
+			Config:            aws.Config{Region: aws.String(DefaultRegion)},
+			Profile:           profile,
+			SharedConfigState: session.SharedConfigEnable,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(opts.Roles) == 0 {
+			out = append(out, credentialSet{session: sess, config: &aws.Config{}})
+			continue
+		}
+		for _, role := range opts.Roles {
+			out = append(out, credentialSet{
+				session: sess,
+				config: &aws.Config{
+					Credentials: stscreds.NewCredentials(sess, role),
+				},
+			})
+		}
+	}
+	return
+}
+
+// getAccountID returns the id of the account the credentials belong to.
+func getAccountID(set credentialSet) (string, error) {
+	res, err := sts.New(set.session, set.config).GetCallerIdentity(
+		&sts.GetCallerIdentityInput{},
+	)
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(res.Account), nil
+}
+
+// expandRegions replaces AllRegions in regions by the list of regions
+// available, and defaults to DefaultRegion if regions is empty.
+func expandRegions(svc ec2iface.EC2API, regions []string) (out []string, err error) {
+	if len(regions) == 0 {
+		return []string{DefaultRegion}, nil
+	}
+	for _, region := range regions {
+		if region != AllRegions {
+			out = append(out, region)
+			continue
+		}
+		res, err := svc.DescribeRegions(&ec2.DescribeRegionsInput{})
+		if err != nil {
+			return nil, err
+		}
+		out = nil
+		for _, r := range res.Regions {
+			out = append(out, aws.StringValue(r.RegionName))
+		}
+		return out, nil
+	}
+	return
+}
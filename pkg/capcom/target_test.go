@@ -0,0 +1,68 @@
+package capcom
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func (m *mockEC2Client) DescribeRegions(
+	in *ec2.DescribeRegionsInput,
+) (
+	out *ec2.DescribeRegionsOutput,
+	err error,
+) {
+	out = &ec2.DescribeRegionsOutput{
+		Regions: []*ec2.Region{
+			{RegionName: aws.String("eu-west-1")},
+			{RegionName: aws.String("us-east-1")},
+		},
+	}
+	return
+}
+
+func TestExpandRegions(t *testing.T) {
+	data := []struct {
+		regions  []string
+		expected []string
+	}{
+		{
+			regions:  nil,
+			expected: []string{DefaultRegion},
+		},
+		{
+			regions:  []string{"eu-west-1", "us-west-2"},
+			expected: []string{"eu-west-1", "us-west-2"},
+		},
+		{
+			regions:  []string{"us-west-2", AllRegions},
+			expected: []string{"eu-west-1", "us-east-1"},
+		},
+	}
+	svc := &mockEC2Client{}
+	for _, tc := range data {
+		out, err := expandRegions(svc, tc.regions)
+		if err != nil {
+			t.Error(err)
+		}
+		if len(out) != len(tc.expected) {
+			t.Fatalf("Unexpected regions %v", out)
+		}
+		for i := range out {
+			if out[i] != tc.expected[i] {
+				t.Errorf("Unexpected region %s != %s", out[i], tc.expected[i])
+			}
+		}
+	}
+}
+
+func TestLocationString(t *testing.T) {
+	if out := (Location{}).String(); out != "" {
+		t.Errorf("Expected empty location, got %s", out)
+	}
+	l := Location{Account: "123456789012", Region: "eu-west-1"}
+	if out := l.String(); out != "123456789012/eu-west-1" {
+		t.Errorf("Unexpected location %s", out)
+	}
+}
@@ -0,0 +1,273 @@
+package capcom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// Snapshot holds the rules of a set of Security Groups, by sgid, at a
+// point in time.
+type Snapshot struct {
+	Taken time.Time
+	Rules map[string][]Rule
+}
+
+// Snapshot takes a Snapshot of the Security Groups matching filter.
+func (c *Client) Snapshot(ctx aws.Context, filter GroupFilter) (Snapshot, error) {
+	sglist, err := c.SecurityGroups(ctx, filter)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	out := Snapshot{
+		Taken: time.Now(),
+		Rules: make(map[string][]Rule),
+	}
+	for _, sg := range sglist {
+		out.Rules[aws.StringValue(sg.GroupId)] = Rules(sg)
+	}
+	return out, nil
+}
+
+// Event reports the rules added to and removed from a Security Group
+// between two snapshots. Diff shows the same changes as text, with
+// added rules prefixed by "+" and removed ones by "-".
+type Event struct {
+	Location
+	Time    time.Time `json:"time"`
+	GroupID string    `json:"group_id"`
+	Added   []Rule    `json:"added,omitempty"`
+	Removed []Rule    `json:"removed,omitempty"`
+	Diff    string    `json:"diff"`
+}
+
+// DiffSnapshots returns an Event for each group whose rules differ
+// between old and current, sorted by sgid. Groups created or deleted in
+// between show all their rules as added or removed.
+func DiffSnapshots(old, current Snapshot) (out []Event) {
+	sgids := make(map[string]bool)
+	for sgid := range old.Rules {
+		sgids[sgid] = true
+	}
+	for sgid := range current.Rules {
+		sgids[sgid] = true
+	}
+	sorted := make([]string, 0, len(sgids))
+	for sgid := range sgids {
+		sorted = append(sorted, sgid)
+	}
+	sort.Strings(sorted)
+	for _, sgid := range sorted {
+		event := Event{
+			Time:    current.Taken,
+			GroupID: sgid,
+			Added:   missingRules(current.Rules[sgid], old.Rules[sgid]),
+			Removed: missingRules(old.Rules[sgid], current.Rules[sgid]),
+		}
+		if len(event.Added)+len(event.Removed) == 0 {
+			continue
+		}
+		var diff []string
+		for _, rule := range event.Removed {
+			diff = append(diff, fmt.Sprintf("- %s", rule))
+		}
+		for _, rule := range event.Added {
+			diff = append(diff, fmt.Sprintf("+ %s", rule))
+		}
+		event.Diff = strings.Join(diff, "\n")
+		out = append(out, event)
+	}
+	return
+}
+
+// missingRules returns the rules in rules not present in other.
+func missingRules(rules, other []Rule) (out []Rule) {
+	present := make(map[Rule]bool)
+	for _, rule := range other {
+		present[rule] = true
+	}
+	for _, rule := range rules {
+		if !present[rule] {
+			out = append(out, rule)
+		}
+	}
+	return
+}
+
+// EventSink receives the events detected by a Watcher.
+type EventSink interface {
+	Send(ctx aws.Context, events []Event) error
+}
+
+// JSONSink writes each event as a line of JSON.
+type JSONSink struct {
+	Writer io.Writer
+}
+
+// Send writes events to the sink Writer.
+func (s JSONSink) Send(ctx aws.Context, events []Event) error {
+	encoder := json.NewEncoder(s.Writer)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MultiSink sends events to several sinks.
+type MultiSink []EventSink
+
+// Send sends events to every sink, even if some fail, returning the
+// first failure.
+func (s MultiSink) Send(ctx aws.Context, events []Event) (err error) {
+	for _, sink := range s {
+		if serr := sink.Send(ctx, events); serr != nil && err == nil {
+			err = serr
+		}
+	}
+	return
+}
+
+// WebhookSink POSTs each event, in JSON format, to URL.
+type WebhookSink struct {
+	URL string
+	// Client posts the events, with a 10 seconds timeout when nil.
+	Client *http.Client
+}
+
+// Send posts events to the sink URL.
+func (s WebhookSink) Send(ctx aws.Context, events []Event) error {
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	for _, event := range events {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequest("POST", s.URL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("Webhook %s responded %s", s.URL, resp.Status)
+		}
+	}
+	return nil
+}
+
+// PushgatewaySink counts the rules added and removed per group, and
+// pushes the counters to a Prometheus Pushgateway.
+type PushgatewaySink struct {
+	pusher  *push.Pusher
+	changes *prometheus.CounterVec
+	last    prometheus.Gauge
+}
+
+// NewPushgatewaySink creates a PushgatewaySink pushing to address.
+func NewPushgatewaySink(address string) *PushgatewaySink {
+	s := &PushgatewaySink{
+		changes: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "capcom",
+				Name:      "rule_changes_total",
+				Help:      "Number of Security Group rules added or removed",
+			},
+			[]string{"group", "change"},
+		),
+		last: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: "capcom",
+				Name:      "last_change_timestamp_seconds",
+				Help:      "Unix timestamp of the last Security Group change detected",
+			},
+		),
+	}
+	s.pusher = push.New(address, "capcom").
+		Collector(s.changes).
+		Collector(s.last)
+	return s
+}
+
+// Send adds events to the counters and pushes them. Events are counted
+// even if pushing fails, and pushed along with the next ones.
+func (s *PushgatewaySink) Send(ctx aws.Context, events []Event) error {
+	for _, event := range events {
+		s.changes.WithLabelValues(event.GroupID, "added").
+			Add(float64(len(event.Added)))
+		s.changes.WithLabelValues(event.GroupID, "removed").
+			Add(float64(len(event.Removed)))
+		s.last.Set(float64(event.Time.Unix()))
+	}
+	return s.pusher.Push()
+}
+
+// Watcher detects changes in the rules of Security Groups by comparing
+// successive snapshots.
+type Watcher struct {
+	Client   *Client
+	Filter   GroupFilter
+	Location Location
+	last     *Snapshot
+}
+
+// Poll takes a new snapshot and returns the changes since the previous
+// one. The first call only records the initial state.
+func (w *Watcher) Poll(ctx aws.Context) ([]Event, error) {
+	snapshot, err := w.Client.Snapshot(ctx, w.Filter)
+	if err != nil {
+		return nil, err
+	}
+	previous := w.last
+	w.last = &snapshot
+	if previous == nil {
+		return nil, nil
+	}
+	events := DiffSnapshots(*previous, snapshot)
+	for i := range events {
+		events[i].Location = w.Location
+	}
+	return events, nil
+}
+
+// Run polls every interval, sending the changes found to sink, until
+// ctx is done. Failures are logged, and changes which couldn't be sent
+// aren't sent again, as some sinks may have received them.
+func (w *Watcher) Run(ctx aws.Context, interval time.Duration, sink EventSink) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		events, err := w.Poll(ctx)
+		if err != nil {
+			log.Printf("Failed taking snapshot: %s\n", err)
+		}
+		if len(events) > 0 {
+			if err := sink.Send(ctx, events); err != nil {
+				log.Printf("Failed sending %d events: %s\n", len(events), err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package capcom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	ssh := Rule{GroupID: "sg-1", Protocol: "tcp", FromPort: 22, ToPort: 22, Peer: "10.0.0.0/8"}
+	web := Rule{GroupID: "sg-1", Protocol: "tcp", FromPort: 80, ToPort: 80, Peer: "0.0.0.0/0"}
+	db := Rule{GroupID: "sg-2", Protocol: "tcp", FromPort: 5432, ToPort: 5432, Peer: "sg-1"}
+	old := Snapshot{
+		Rules: map[string][]Rule{
+			"sg-1": {ssh},
+			"sg-2": {db},
+		},
+	}
+	current := Snapshot{
+		Taken: time.Unix(1500000000, 0),
+		Rules: map[string][]Rule{
+			"sg-1": {web},
+			"sg-2": {db},
+		},
+	}
+	events := DiffSnapshots(old, current)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, found %v", events)
+	}
+	event := events[0]
+	if event.GroupID != "sg-1" ||
+		len(event.Added) != 1 || event.Added[0] != web ||
+		len(event.Removed) != 1 || event.Removed[0] != ssh ||
+		!event.Time.Equal(current.Taken) {
+		t.Errorf("Unexpected event %v", event)
+	}
+	expected := "- sg-1 ingress 22/tcp 10.0.0.0/8\n+ sg-1 ingress 80/tcp 0.0.0.0/0"
+	if event.Diff != expected {
+		t.Errorf("%q is not %q", event.Diff, expected)
+	}
+	if events := DiffSnapshots(current, current); len(events) != 0 {
+		t.Errorf("Unexpected events %v", events)
+	}
+	deleted := Snapshot{Rules: map[string][]Rule{"sg-1": {web}}}
+	events = DiffSnapshots(current, deleted)
+	if len(events) != 1 || events[0].GroupID != "sg-2" || len(events[0].Removed) != 1 {
+		t.Errorf("Unexpected events for deleted group %v", events)
+	}
+}
+
+type changingEC2Client struct {
+	mockEC2Client
+	calls int
+}
+
+func (m *changingEC2Client) DescribeSecurityGroupsWithContext(
+	ctx aws.Context,
+	in *ec2.DescribeSecurityGroupsInput,
+	opts ...request.Option,
+) (
+	*ec2.DescribeSecurityGroupsOutput,
+	error,
+) {
+	m.calls++
+	out, err := m.DescribeSecurityGroups(in)
+	if m.calls > 1 {
+		out.SecurityGroups[0].IpPermissions[0].IpRanges[0].CidrIp = aws.String("0.0.0.0/0")
+	}
+	return out, err
+}
+
+func TestWatcherPoll(t *testing.T) {
+	w := &Watcher{
+		Client:   NewClient(&changingEC2Client{}),
+		Location: Location{Account: "1234", Region: "us-east-1"},
+	}
+	events, err := w.Poll(aws.BackgroundContext())
+	if err != nil || len(events) != 0 {
+		t.Fatalf("Unexpected first poll %v %v", events, err)
+	}
+	events, err = w.Poll(aws.BackgroundContext())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Location != w.Location {
+		t.Errorf("Unexpected events %v", events)
+	}
+}
+
+func TestJSONSink(t *testing.T) {
+	var b bytes.Buffer
+	events := []Event{{GroupID: "sg-1"}, {GroupID: "sg-2"}}
+	if err := (JSONSink{Writer: &b}).Send(aws.BackgroundContext(), events); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, found %q", b.String())
+	}
+	var event Event
+	if err := json.Unmarshal([]byte(lines[1]), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.GroupID != "sg-2" {
+		t.Errorf("Unexpected event %v", event)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received []Event
+	server := httptest.NewServer(http.HandlerFunc(func(
+		w http.ResponseWriter,
+		r *http.Request,
+	) {
+		var event Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, event)
+	}))
+	defer server.Close()
+	sink := WebhookSink{URL: server.URL}
+	if err := sink.Send(aws.BackgroundContext(), []Event{{GroupID: "sg-1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 1 || received[0].GroupID != "sg-1" {
+		t.Errorf("Unexpected events received %v", received)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(
+		w http.ResponseWriter,
+		r *http.Request,
+	) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	if err := (WebhookSink{URL: failing.URL}).Send(aws.BackgroundContext(), []Event{{}}); err == nil {
+		t.Error("Expected error from failing webhook")
+	}
+
+	ctx, cancel := context.WithCancel(aws.BackgroundContext())
+	cancel()
+	if err := sink.Send(ctx, []Event{{GroupID: "sg-2"}}); err == nil {
+		t.Error("Expected error from cancelled context")
+	}
+}
+
+// recordingSink records the events sent, failing if err is set.
+type recordingSink struct {
+	events []Event
+	err    error
+}
+
+func (s *recordingSink) Send(ctx aws.Context, events []Event) error {
+	s.events = append(s.events, events...)
+	return s.err
+}
+
+func TestMultiSink(t *testing.T) {
+	failing := &recordingSink{err: errors.New("Push failed")}
+	working := &recordingSink{}
+	err := MultiSink{failing, working}.Send(
+		aws.BackgroundContext(),
+		[]Event{{GroupID: "sg-1"}},
+	)
+	if err == nil || err.Error() != "Push failed" {
+		t.Errorf("Expected: Push failed, but got %v", err)
+	}
+	if len(working.events) != 1 {
+		t.Errorf("Events not sent after a failing sink: %v", working.events)
+	}
+}
@@ -0,0 +1,128 @@
+package odin
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+)
+
+// GetSnapshot returns the snapshot with the id.
+func GetSnapshot(
+	id string,
+	svc rdsiface.RDSAPI,
+) (
+	result *rds.DBSnapshot,
+	err error,
+) {
+	output, err := svc.DescribeDBSnapshots(
+		&rds.DescribeDBSnapshotsInput{
+			DBSnapshotIdentifier: aws.String(id),
+		},
+	)
+	if err != nil {
+		return
+	}
+	if len(output.DBSnapshots) == 0 {
+		return nil, fmt.Errorf("No such snapshot %s", id)
+	}
+	return output.DBSnapshots[0], nil
+}
+
+// CopySnapshotInput returns CopyDBSnapshotInput to copy snapshot, from
+// sourceRegion, as target. It is to be sent to the RDS of the
+// destination region, where kmsKeyID must be, if the snapshot is
+// encrypted. Target defaults to the identifier of the snapshot, without
+// the "rds:" prefix of automated snapshots.
+func CopySnapshotInput(
+	snapshot *rds.DBSnapshot,
+	sourceRegion string,
+	target string,
+	kmsKeyID string,
+) (
+	result *rds.CopyDBSnapshotInput,
+	err error,
+) {
+	if snapshot.DBSnapshotArn == nil {
+		return nil, fmt.Errorf(
+			"Snapshot %s has no ARN to copy it from",
+			aws.StringValue(snapshot.DBSnapshotIdentifier),
+		)
+	}
+	if aws.BoolValue(snapshot.Encrypted) && kmsKeyID == "" {
+		return nil, fmt.Errorf(
+			"Snapshot %s is encrypted, a KMS key of the destination region is needed",
+			aws.StringValue(snapshot.DBSnapshotIdentifier),
+		)
+	}
+	if target == "" {
+		target = strings.TrimPrefix(
+			aws.StringValue(snapshot.DBSnapshotIdentifier),
+			"rds:",
+		)
+	}
+	result = &rds.CopyDBSnapshotInput{
+		CopyTags:                   aws.Bool(true),
+		SourceDBSnapshotIdentifier: snapshot.DBSnapshotArn,
+		SourceRegion:               aws.String(sourceRegion),
+		TargetDBSnapshotIdentifier: aws.String(target),
+	}
+	if kmsKeyID != "" {
+		result.KmsKeyId = aws.String(kmsKeyID)
+	}
+	if err = validate(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+var accountFormat = regexp.MustCompile(`^\d{12}$`)
+
+// ShareSnapshotInput returns ModifyDBSnapshotAttributeInput to allow
+// the accounts to restore snapshot, or to stop allowing them if revoke
+// is true. "all" makes the snapshot public.
+func ShareSnapshotInput(
+	snapshot *rds.DBSnapshot,
+	accounts []string,
+	revoke bool,
+) (
+	result *rds.ModifyDBSnapshotAttributeInput,
+	err error,
+) {
+	id := aws.StringValue(snapshot.DBSnapshotIdentifier)
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("No accounts to share %s with", id)
+	}
+	if aws.StringValue(snapshot.SnapshotType) == "automated" {
+		return nil, fmt.Errorf(
+			"Automated snapshot %s can't be shared, copy it first",
+			id,
+		)
+	}
+	values := []*string{}
+	for _, account := range accounts {
+		if account != "all" && !accountFormat.MatchString(account) {
+			return nil, fmt.Errorf("%s is not an AWS account ID", account)
+		}
+		if account == "all" && aws.BoolValue(snapshot.Encrypted) {
+			return nil, fmt.Errorf("Encrypted snapshot %s can't be public", id)
+		}
+		values = append(values, aws.String(account))
+	}
+	result = &rds.ModifyDBSnapshotAttributeInput{
+		AttributeName:        aws.String("restore"),
+		DBSnapshotIdentifier: snapshot.DBSnapshotIdentifier,
+	}
+	if revoke {
+		result.ValuesToRemove = values
+	} else {
+		result.ValuesToAdd = values
+	}
+	if err = validate(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
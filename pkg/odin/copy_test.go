@@ -0,0 +1,203 @@
+package odin_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+
+	"github.com/poka-yoke/spaceflight/internal/test"
+	"github.com/poka-yoke/spaceflight/internal/test/mocks"
+	"github.com/poka-yoke/spaceflight/pkg/odin"
+)
+
+var automatedSnapshot = &rds.DBSnapshot{
+	DBInstanceIdentifier: aws.String("production-rds"),
+	DBSnapshotArn:        aws.String("arn:aws:rds:us-east-1:0:snapshot:rds:production-2018-06-11"),
+	DBSnapshotIdentifier: aws.String("rds:production-2018-06-11"),
+	SnapshotType:         aws.String("automated"),
+}
+
+var encryptedSnapshot = &rds.DBSnapshot{
+	DBInstanceIdentifier: aws.String("production-rds"),
+	DBSnapshotArn:        aws.String("arn:aws:rds:us-east-1:0:snapshot:production-encrypted"),
+	DBSnapshotIdentifier: aws.String("production-encrypted"),
+	Encrypted:            aws.Bool(true),
+	SnapshotType:         aws.String("manual"),
+}
+
+func TestGetSnapshot(t *testing.T) {
+	svc := mocks.NewRDSClient()
+	svc.AddSnapshots([]*rds.DBSnapshot{exampleSnapshot1, automatedSnapshot})
+	tt := []struct {
+		test.Case
+		id string
+	}{
+		{
+			Case: test.Case{Expected: automatedSnapshot},
+			id:   "rds:production-2018-06-11",
+		},
+		{
+			Case: test.Case{ExpectedError: "No such snapshot im-not-here"},
+			id:   "im-not-here",
+		},
+	}
+	for _, tc := range tt {
+		actual, err := odin.GetSnapshot(tc.id, svc)
+		tc.Check(actual, err, t)
+	}
+}
+
+func TestCopySnapshotInput(t *testing.T) {
+	tt := []struct {
+		test.Case
+		name     string
+		snapshot *rds.DBSnapshot
+		target   string
+		kmsKeyID string
+	}{
+		{
+			Case: test.Case{
+				Expected: &rds.CopyDBSnapshotInput{
+					CopyTags:                   aws.Bool(true),
+					SourceDBSnapshotIdentifier: automatedSnapshot.DBSnapshotArn,
+					SourceRegion:               aws.String("us-east-1"),
+					TargetDBSnapshotIdentifier: aws.String("production-2018-06-11"),
+				},
+			},
+			name:     "Automated snapshot",
+			snapshot: automatedSnapshot,
+		},
+		{
+			Case: test.Case{
+				Expected: &rds.CopyDBSnapshotInput{
+					CopyTags:                   aws.Bool(true),
+					KmsKeyId:                   aws.String("alias/dr"),
+					SourceDBSnapshotIdentifier: encryptedSnapshot.DBSnapshotArn,
+					SourceRegion:               aws.String("us-east-1"),
+					TargetDBSnapshotIdentifier: aws.String("production-dr"),
+				},
+			},
+			name:     "Encrypted snapshot",
+			snapshot: encryptedSnapshot,
+			target:   "production-dr",
+			kmsKeyID: "alias/dr",
+		},
+		{
+			Case: test.Case{
+				ExpectedError: "Snapshot production-encrypted is encrypted, a KMS key of the destination region is needed",
+			},
+			name:     "Encrypted snapshot without key",
+			snapshot: encryptedSnapshot,
+		},
+		{
+			Case: test.Case{
+				ExpectedError: "Snapshot develop has no ARN to copy it from",
+			},
+			name: "Snapshot without ARN",
+			snapshot: &rds.DBSnapshot{
+				DBSnapshotIdentifier: aws.String("develop"),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(
+			tc.name,
+			func(t *testing.T) {
+				actual, err := odin.CopySnapshotInput(
+					tc.snapshot,
+					"us-east-1",
+					tc.target,
+					tc.kmsKeyID,
+				)
+				tc.Check(actual, err, t)
+			},
+		)
+	}
+}
+
+func TestShareSnapshotInput(t *testing.T) {
+	manual := &rds.DBSnapshot{
+		DBSnapshotIdentifier: aws.String("production-manual"),
+		SnapshotType:         aws.String("manual"),
+	}
+	tt := []struct {
+		test.Case
+		name     string
+		snapshot *rds.DBSnapshot
+		accounts []string
+		revoke   bool
+	}{
+		{
+			Case: test.Case{
+				Expected: &rds.ModifyDBSnapshotAttributeInput{
+					AttributeName:        aws.String("restore"),
+					DBSnapshotIdentifier: manual.DBSnapshotIdentifier,
+					ValuesToAdd: aws.StringSlice(
+						[]string{"123456789012", "210987654321"},
+					),
+				},
+			},
+			name:     "Share",
+			snapshot: manual,
+			accounts: []string{"123456789012", "210987654321"},
+		},
+		{
+			Case: test.Case{
+				Expected: &rds.ModifyDBSnapshotAttributeInput{
+					AttributeName:        aws.String("restore"),
+					DBSnapshotIdentifier: manual.DBSnapshotIdentifier,
+					ValuesToRemove:       aws.StringSlice([]string{"all"}),
+				},
+			},
+			name:     "Revoke public access",
+			snapshot: manual,
+			accounts: []string{"all"},
+			revoke:   true,
+		},
+		{
+			Case: test.Case{
+				ExpectedError: "No accounts to share production-manual with",
+			},
+			name:     "No accounts",
+			snapshot: manual,
+		},
+		{
+			Case: test.Case{
+				ExpectedError: "1234 is not an AWS account ID",
+			},
+			name:     "Invalid account",
+			snapshot: manual,
+			accounts: []string{"1234"},
+		},
+		{
+			Case: test.Case{
+				ExpectedError: "Automated snapshot rds:production-2018-06-11 can't be shared, copy it first",
+			},
+			name:     "Automated snapshot",
+			snapshot: automatedSnapshot,
+			accounts: []string{"123456789012"},
+		},
+		{
+			Case: test.Case{
+				ExpectedError: "Encrypted snapshot production-encrypted can't be public",
+			},
+			name:     "Public encrypted snapshot",
+			snapshot: encryptedSnapshot,
+			accounts: []string{"all"},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(
+			tc.name,
+			func(t *testing.T) {
+				actual, err := odin.ShareSnapshotInput(
+					tc.snapshot,
+					tc.accounts,
+					tc.revoke,
+				)
+				tc.Check(actual, err, t)
+			},
+		)
+	}
+}
@@ -0,0 +1,241 @@
+package odin
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+)
+
+// InstanceInfo summarizes the state of an RDS instance.
+type InstanceInfo struct {
+	Identifier           string            `json:"identifier"`
+	Status               string            `json:"status"`
+	Engine               string            `json:"engine"`
+	EngineVersion        string            `json:"engine_version"`
+	Class                string            `json:"class"`
+	Endpoint             string            `json:"endpoint,omitempty"`
+	Port                 int64             `json:"port,omitempty"`
+	Storage              int64             `json:"storage"`
+	StorageType          string            `json:"storage_type"`
+	IOPS                 int64             `json:"iops,omitempty"`
+	MultiAZ              bool              `json:"multi_az"`
+	Encrypted            bool              `json:"encrypted"`
+	PendingModifications map[string]string `json:"pending_modifications,omitempty"`
+	ParameterGroups      map[string]string `json:"parameter_groups,omitempty"`
+	BackupWindow         string            `json:"backup_window,omitempty"`
+	BackupRetention      int64             `json:"backup_retention"`
+	MaintenanceWindow    string            `json:"maintenance_window,omitempty"`
+	Tags                 map[string]string `json:"tags,omitempty"`
+	LatestSnapshot       *SnapshotInfo     `json:"latest_snapshot,omitempty"`
+}
+
+// SnapshotInfo identifies a snapshot of an instance.
+type SnapshotInfo struct {
+	Identifier string `json:"identifier"`
+	Created    string `json:"created,omitempty"`
+	Status     string `json:"status"`
+}
+
+// NewInstanceInfo returns the InstanceInfo for db.
+func NewInstanceInfo(db *rds.DBInstance) InstanceInfo {
+	info := InstanceInfo{
+		Identifier:        aws.StringValue(db.DBInstanceIdentifier),
+		Status:            aws.StringValue(db.DBInstanceStatus),
+		Engine:            aws.StringValue(db.Engine),
+		EngineVersion:     aws.StringValue(db.EngineVersion),
+		Class:             aws.StringValue(db.DBInstanceClass),
+		Storage:           aws.Int64Value(db.AllocatedStorage),
+		StorageType:       aws.StringValue(db.StorageType),
+		IOPS:              aws.Int64Value(db.Iops),
+		MultiAZ:           aws.BoolValue(db.MultiAZ),
+		Encrypted:         aws.BoolValue(db.StorageEncrypted),
+		BackupWindow:      aws.StringValue(db.PreferredBackupWindow),
+		BackupRetention:   aws.Int64Value(db.BackupRetentionPeriod),
+		MaintenanceWindow: aws.StringValue(db.PreferredMaintenanceWindow),
+	}
+	if db.Endpoint != nil {
+		info.Endpoint = aws.StringValue(db.Endpoint.Address)
+		info.Port = aws.Int64Value(db.Endpoint.Port)
+	}
+	if pending := pendingModifications(db.PendingModifiedValues); len(pending) > 0 {
+		info.PendingModifications = pending
+	}
+	if len(db.DBParameterGroups) > 0 {
+		info.ParameterGroups = make(map[string]string)
+		for _, group := range db.DBParameterGroups {
+			info.ParameterGroups[aws.StringValue(group.DBParameterGroupName)] =
+				aws.StringValue(group.ParameterApplyStatus)
+		}
+	}
+	return info
+}
+
+// pendingModifications returns the values of the modifications to be
+// applied to an instance, by name.
+func pendingModifications(values *rds.PendingModifiedValues) map[string]string {
+	out := make(map[string]string)
+	if values == nil {
+		return out
+	}
+	set := func(name string, value interface{}) {
+		switch v := value.(type) {
+		case *string:
+			if v != nil {
+				out[name] = *v
+			}
+		case *int64:
+			if v != nil {
+				out[name] = fmt.Sprint(*v)
+			}
+		case *bool:
+			if v != nil {
+				out[name] = fmt.Sprint(*v)
+			}
+		}
+	}
+	set("storage", values.AllocatedStorage)
+	set("backup_retention", values.BackupRetentionPeriod)
+	set("ca_certificate", values.CACertificateIdentifier)
+	set("class", values.DBInstanceClass)
+	set("identifier", values.DBInstanceIdentifier)
+	set("subnet_group", values.DBSubnetGroupName)
+	set("engine_version", values.EngineVersion)
+	set("iops", values.Iops)
+	set("license_model", values.LicenseModel)
+	set("multi_az", values.MultiAZ)
+	set("port", values.Port)
+	set("storage_type", values.StorageType)
+	if values.MasterUserPassword != nil {
+		out["master_password"] = "****"
+	}
+	return out
+}
+
+// InstanceFilter selects instances by engine, status, class and tags.
+// Empty fields match every instance. Tags with an empty value match
+// instances having the tag, whatever its value.
+type InstanceFilter struct {
+	Engine string
+	Status string
+	Class  string
+	Tags   map[string]string
+}
+
+// Match returns true if the instance described by info passes the
+// filter.
+func (f InstanceFilter) Match(info InstanceInfo) bool {
+	switch {
+	case f.Engine != "" && f.Engine != info.Engine:
+		return false
+	case f.Status != "" && f.Status != info.Status:
+		return false
+	case f.Class != "" && f.Class != info.Class:
+		return false
+	}
+	for key, value := range f.Tags {
+		tag, ok := info.Tags[key]
+		if !ok || value != "" && value != tag {
+			return false
+		}
+	}
+	return true
+}
+
+// instanceTags returns the tags of db.
+func instanceTags(
+	db *rds.DBInstance,
+	svc rdsiface.RDSAPI,
+) (map[string]string, error) {
+	out, err := svc.ListTagsForResource(
+		&rds.ListTagsForResourceInput{
+			ResourceName: db.DBInstanceArn,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string]string)
+	for _, tag := range out.TagList {
+		tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+	return tags, nil
+}
+
+// ListInstances returns the instances passing filter, ordered as RDS
+// returns them. Tags are only looked up when filtering by them.
+func ListInstances(
+	filter InstanceFilter,
+	svc rdsiface.RDSAPI,
+) (
+	result []InstanceInfo,
+	err error,
+) {
+	result = []InstanceInfo{}
+	input := &rds.DescribeDBInstancesInput{}
+	for {
+		output, err := svc.DescribeDBInstances(input)
+		if err != nil {
+			return nil, err
+		}
+		for _, db := range output.DBInstances {
+			info := NewInstanceInfo(db)
+			if len(filter.Tags) > 0 {
+				if info.Tags, err = instanceTags(db, svc); err != nil {
+					return nil, err
+				}
+			}
+			if filter.Match(info) {
+				result = append(result, info)
+			}
+		}
+		if aws.StringValue(output.Marker) == "" {
+			break
+		}
+		input.Marker = output.Marker
+	}
+	return
+}
+
+// DescribeInstance returns the InstanceInfo of the instance with the
+// id, including its tags and latest snapshot.
+func DescribeInstance(
+	id string,
+	svc rdsiface.RDSAPI,
+) (
+	result *InstanceInfo,
+	err error,
+) {
+	output, err := svc.DescribeDBInstances(
+		&rds.DescribeDBInstancesInput{
+			DBInstanceIdentifier: aws.String(id),
+		},
+	)
+	if err != nil {
+		return
+	}
+	if len(output.DBInstances) == 0 {
+		return nil, fmt.Errorf("No such instance %s", id)
+	}
+	db := output.DBInstances[0]
+	info := NewInstanceInfo(db)
+	if info.Tags, err = instanceTags(db, svc); err != nil {
+		return
+	}
+	snapshots, err := ListSnapshots(id, svc)
+	if err != nil {
+		return
+	}
+	if len(snapshots) > 0 {
+		info.LatestSnapshot = &SnapshotInfo{
+			Identifier: aws.StringValue(snapshots[0].DBSnapshotIdentifier),
+			Status:     aws.StringValue(snapshots[0].Status),
+		}
+		if snapshots[0].SnapshotCreateTime != nil {
+			info.LatestSnapshot.Created =
+				snapshots[0].SnapshotCreateTime.Format(RFC8601)
+		}
+	}
+	return &info, nil
+}
@@ -0,0 +1,214 @@
+package odin_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+
+	"github.com/poka-yoke/spaceflight/internal/test"
+	"github.com/poka-yoke/spaceflight/internal/test/mocks"
+	"github.com/poka-yoke/spaceflight/pkg/odin"
+)
+
+var exampleInstance1 = &rds.DBInstance{
+	AllocatedStorage:      aws.Int64(100),
+	BackupRetentionPeriod: aws.Int64(7),
+	DBInstanceArn:         aws.String("arn:aws:rds:us-east-1:0:db:production-rds"),
+	DBInstanceClass:       aws.String("db.m4.large"),
+	DBInstanceIdentifier:  exampleSnapshot1DBID,
+	DBInstanceStatus:      aws.String("available"),
+	DBParameterGroups: []*rds.DBParameterGroupStatus{
+		{
+			DBParameterGroupName: aws.String("pg96"),
+			ParameterApplyStatus: aws.String("pending-reboot"),
+		},
+	},
+	Endpoint: &rds.Endpoint{
+		Address: aws.String("production-rds.0.us-east-1.rds.amazonaws.com"),
+		Port:    aws.Int64(5432),
+	},
+	Engine:        aws.String("postgres"),
+	EngineVersion: aws.String("9.6.6"),
+	MultiAZ:       aws.Bool(true),
+	PendingModifiedValues: &rds.PendingModifiedValues{
+		DBInstanceClass: aws.String("db.m4.xlarge"),
+	},
+	PreferredBackupWindow:      aws.String("03:00-04:00"),
+	PreferredMaintenanceWindow: aws.String("sun:05:00-sun:06:00"),
+	StorageType:                aws.String("gp2"),
+}
+
+var exampleInstance2 = &rds.DBInstance{
+	AllocatedStorage:     aws.Int64(10),
+	DBInstanceArn:        aws.String("arn:aws:rds:us-east-1:0:db:develop-rds"),
+	DBInstanceClass:      aws.String("db.t2.micro"),
+	DBInstanceIdentifier: exampleSnapshot2DBID,
+	DBInstanceStatus:     aws.String("stopped"),
+	Engine:               aws.String("mysql"),
+	EngineVersion:        aws.String("5.7.21"),
+	StorageType:          aws.String("standard"),
+}
+
+var exampleInstance1Info = odin.InstanceInfo{
+	Identifier:    "production-rds",
+	Status:        "available",
+	Engine:        "postgres",
+	EngineVersion: "9.6.6",
+	Class:         "db.m4.large",
+	Endpoint:      "production-rds.0.us-east-1.rds.amazonaws.com",
+	Port:          5432,
+	Storage:       100,
+	StorageType:   "gp2",
+	MultiAZ:       true,
+	PendingModifications: map[string]string{
+		"class": "db.m4.xlarge",
+	},
+	ParameterGroups: map[string]string{
+		"pg96": "pending-reboot",
+	},
+	BackupWindow:      "03:00-04:00",
+	BackupRetention:   7,
+	MaintenanceWindow: "sun:05:00-sun:06:00",
+}
+
+var exampleInstance2Info = odin.InstanceInfo{
+	Identifier:    "develop-rds",
+	Status:        "stopped",
+	Engine:        "mysql",
+	EngineVersion: "5.7.21",
+	Class:         "db.t2.micro",
+	Storage:       10,
+	StorageType:   "standard",
+}
+
+var exampleInstanceTags = map[string][]*rds.Tag{
+	"arn:aws:rds:us-east-1:0:db:production-rds": {
+		{Key: aws.String("env"), Value: aws.String("production")},
+	},
+	"arn:aws:rds:us-east-1:0:db:develop-rds": {
+		{Key: aws.String("env"), Value: aws.String("develop")},
+		{Key: aws.String("team"), Value: aws.String("web")},
+	},
+}
+
+func TestListInstances(t *testing.T) {
+	production := exampleInstance1Info
+	production.Tags = map[string]string{"env": "production"}
+	tt := []struct {
+		test.Case
+		name   string
+		filter odin.InstanceFilter
+	}{
+		{
+			Case: test.Case{
+				Expected: []odin.InstanceInfo{
+					exampleInstance1Info,
+					exampleInstance2Info,
+				},
+			},
+			name: "No filter",
+		},
+		{
+			Case: test.Case{
+				Expected: []odin.InstanceInfo{exampleInstance2Info},
+			},
+			name:   "Engine",
+			filter: odin.InstanceFilter{Engine: "mysql"},
+		},
+		{
+			Case: test.Case{
+				Expected: []odin.InstanceInfo{exampleInstance1Info},
+			},
+			name:   "Status and class",
+			filter: odin.InstanceFilter{Status: "available", Class: "db.m4.large"},
+		},
+		{
+			Case: test.Case{
+				Expected: []odin.InstanceInfo{},
+			},
+			name:   "Nothing matching",
+			filter: odin.InstanceFilter{Engine: "postgres", Status: "stopped"},
+		},
+		{
+			Case: test.Case{
+				Expected: []odin.InstanceInfo{production},
+			},
+			name:   "Tag value",
+			filter: odin.InstanceFilter{Tags: map[string]string{"env": "production"}},
+		},
+		{
+			Case: test.Case{
+				Expected: []odin.InstanceInfo{},
+			},
+			name: "Tag key",
+			filter: odin.InstanceFilter{
+				Tags: map[string]string{"team": "", "env": "production"},
+			},
+		},
+	}
+	svc := mocks.NewRDSClient()
+	svc.AddInstances([]*rds.DBInstance{exampleInstance1, exampleInstance2})
+	for arn, tags := range exampleInstanceTags {
+		svc.AddTags(arn, tags)
+	}
+	for _, tc := range tt {
+		t.Run(
+			tc.name,
+			func(t *testing.T) {
+				actual, err := odin.ListInstances(tc.filter, svc)
+				tc.Check(actual, err, t)
+			},
+		)
+	}
+}
+
+func TestDescribeInstance(t *testing.T) {
+	production := exampleInstance1Info
+	production.Tags = map[string]string{"env": "production"}
+	production.LatestSnapshot = &odin.SnapshotInfo{
+		Identifier: "rds:production-2015-06-11",
+		Created:    "2015-06-11T22:00:00+00:00",
+		Status:     "available",
+	}
+	develop := exampleInstance2Info
+	develop.Tags = map[string]string{"env": "develop", "team": "web"}
+	tt := []struct {
+		test.Case
+		name string
+		id   string
+	}{
+		{
+			Case: test.Case{Expected: &production},
+			name: "Instance with snapshot",
+			id:   "production-rds",
+		},
+		{
+			Case: test.Case{Expected: &develop},
+			name: "Instance without snapshot",
+			id:   "develop-rds",
+		},
+		{
+			Case: test.Case{
+				ExpectedError: "DBInstanceNotFound: No such instance im-not-here",
+			},
+			name: "Non-existing instance",
+			id:   "im-not-here",
+		},
+	}
+	svc := mocks.NewRDSClient()
+	svc.AddInstances([]*rds.DBInstance{exampleInstance1, exampleInstance2})
+	svc.AddSnapshots([]*rds.DBSnapshot{exampleSnapshot1})
+	for arn, tags := range exampleInstanceTags {
+		svc.AddTags(arn, tags)
+	}
+	for _, tc := range tt {
+		t.Run(
+			tc.name,
+			func(t *testing.T) {
+				actual, err := odin.DescribeInstance(tc.id, svc)
+				tc.Check(actual, err, t)
+			},
+		)
+	}
+}
@@ -2,6 +2,7 @@ package odin
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/rds"
@@ -10,7 +11,9 @@ import (
 
 // Instance holds parameters needed for any operations related to
 // instances and provides methods to obtain the AWS structures needed
-// to perform them.
+// to perform them. Engine defaults to the engine of the snapshot used,
+// if any, or DefaultEngine, while empty or zero options keep the RDS
+// defaults.
 type Instance struct {
 	Identifier           string
 	Type                 string
@@ -22,6 +25,41 @@ type Instance struct {
 	OriginalInstanceName string
 	LastSnapshot         *rds.DBSnapshot
 	FinalSnapshotID      string
+
+	Engine                string
+	EngineVersion         string
+	ParameterGroupName    string
+	OptionGroupName       string
+	StorageType           string
+	IOPS                  int64
+	MultiAZ               bool
+	Encrypted             bool
+	KMSKeyID              string
+	BackupRetentionPeriod int64
+	MaintenanceWindow     string
+}
+
+// securityGroupIDs returns the non empty security groups of the
+// instance.
+func (i Instance) securityGroupIDs() (out []*string) {
+	out = []*string{}
+	for _, sgid := range i.SecurityGroups {
+		if sgid != "" {
+			out = append(out, aws.String(sgid))
+		}
+	}
+	return
+}
+
+// engine returns the engine for the instance.
+func (i Instance) engine() string {
+	switch {
+	case i.Engine != "":
+		return i.Engine
+	case i.LastSnapshot != nil && i.LastSnapshot.Engine != nil:
+		return *i.LastSnapshot.Engine
+	}
+	return DefaultEngine
 }
 
 // CloneDBInput returns CreateDBInstanceInput for the instance with
@@ -46,13 +84,11 @@ func (i Instance) CreateDBInput() (result *rds.CreateDBInstanceInput, err error)
 		DBInstanceIdentifier: &i.Identifier,
 		DBSubnetGroupName:    &i.SubnetGroupName,
 		DBInstanceClass:      &i.Type,
-		DBSecurityGroups: []*string{
-			aws.String("default"),
-		},
-		Engine:             aws.String("postgres"),
-		EngineVersion:      aws.String("9.4.11"),
-		MasterUsername:     &i.User,
-		MasterUserPassword: &i.Password,
+		Engine:               aws.String(i.engine()),
+		MasterUsername:       &i.User,
+		MasterUserPassword:   &i.Password,
+		MultiAZ:              aws.Bool(i.MultiAZ),
+		StorageEncrypted:     aws.Bool(i.Encrypted),
 		Tags: []*rds.Tag{
 			{
 				Key:   aws.String("Name"),
@@ -60,9 +96,42 @@ func (i Instance) CreateDBInput() (result *rds.CreateDBInstanceInput, err error)
 			},
 		},
 	}
+	if sgids := i.securityGroupIDs(); len(sgids) > 0 {
+		result.VpcSecurityGroupIds = sgids
+	}
 	if i.LastSnapshot != nil {
 		result.AllocatedStorage = i.LastSnapshot.AllocatedStorage
 		result.MasterUsername = i.LastSnapshot.MasterUsername
+		if i.EngineVersion == "" && i.Engine == "" {
+			result.EngineVersion = i.LastSnapshot.EngineVersion
+		}
+	}
+	if i.EngineVersion != "" {
+		result.EngineVersion = aws.String(i.EngineVersion)
+	}
+	if i.ParameterGroupName != "" {
+		result.DBParameterGroupName = aws.String(i.ParameterGroupName)
+	}
+	if i.OptionGroupName != "" {
+		result.OptionGroupName = aws.String(i.OptionGroupName)
+	}
+	if i.StorageType != "" {
+		result.StorageType = aws.String(i.StorageType)
+	}
+	if i.IOPS != 0 {
+		result.Iops = aws.Int64(i.IOPS)
+	}
+	if i.KMSKeyID != "" {
+		result.KmsKeyId = aws.String(i.KMSKeyID)
+	}
+	if i.BackupRetentionPeriod != 0 {
+		result.BackupRetentionPeriod = aws.Int64(i.BackupRetentionPeriod)
+	}
+	if i.MaintenanceWindow != "" {
+		result.PreferredMaintenanceWindow = aws.String(i.MaintenanceWindow)
+	}
+	if err = i.validateOptions(*result.AllocatedStorage); err != nil {
+		return nil, err
 	}
 	if err = validate(result); err != nil {
 		return nil, err
@@ -90,16 +159,22 @@ func (i Instance) DeleteDBInput() (result *rds.DeleteDBInstanceInput, err error)
 func (i Instance) ModifyDBInput(
 	applyNow bool,
 ) (result *rds.ModifyDBInstanceInput, err error) {
-	SecurityGroups := []*string{}
-	for _, sgid := range i.SecurityGroups {
-		SecurityGroups = append(SecurityGroups, aws.String(sgid))
-	}
 	result = &rds.ModifyDBInstanceInput{
 		DBInstanceIdentifier: &i.Identifier,
 		DBInstanceClass:      &i.Type,
-		VpcSecurityGroupIds:  SecurityGroups,
+		VpcSecurityGroupIds:  i.securityGroupIDs(),
 		ApplyImmediately:     aws.Bool(applyNow),
 	}
+	// Restores can't set these, so they are applied afterwards.
+	if i.ParameterGroupName != "" {
+		result.DBParameterGroupName = aws.String(i.ParameterGroupName)
+	}
+	if i.BackupRetentionPeriod != 0 {
+		result.BackupRetentionPeriod = aws.Int64(i.BackupRetentionPeriod)
+	}
+	if i.MaintenanceWindow != "" {
+		result.PreferredMaintenanceWindow = aws.String(i.MaintenanceWindow)
+	}
 	if err = validate(result); err != nil {
 		return nil, err
 	}
@@ -119,12 +194,107 @@ func (i Instance) RestoreDBInput(
 		return nil, err
 	}
 	i = *instance
+	err = i.validateRestore(
+		"snapshot",
+		aws.StringValue(i.LastSnapshot.Engine),
+		aws.Int64Value(i.LastSnapshot.AllocatedStorage),
+	)
+	if err != nil {
+		return nil, err
+	}
 	result = &rds.RestoreDBInstanceFromDBSnapshotInput{
 		DBInstanceClass:      &i.Type,
 		DBInstanceIdentifier: &i.Identifier,
 		DBSnapshotIdentifier: i.LastSnapshot.DBSnapshotIdentifier,
 		DBSubnetGroupName:    &i.SubnetGroupName,
-		Engine:               aws.String("postgres"),
+		Engine:               aws.String(i.engine()),
+		MultiAZ:              aws.Bool(i.MultiAZ),
+	}
+	if i.OptionGroupName != "" {
+		result.OptionGroupName = aws.String(i.OptionGroupName)
+	}
+	if i.StorageType != "" {
+		result.StorageType = aws.String(i.StorageType)
+	}
+	if i.IOPS != 0 {
+		result.Iops = aws.Int64(i.IOPS)
+	}
+	if err = validate(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PointInTimeRestoreDBInput returns RestoreDBInstanceToPointInTimeInput
+// for the instance, to restore the original instance as it was at
+// restoreTime, or at the latest restorable time if it is zero.
+func (i Instance) PointInTimeRestoreDBInput(
+	restoreTime time.Time,
+	svc rdsiface.RDSAPI,
+) (
+	result *rds.RestoreDBInstanceToPointInTimeInput,
+	err error,
+) {
+	if i.OriginalInstanceName == "" {
+		return nil, fmt.Errorf("Original Instance Name was empty")
+	}
+	output, err := svc.DescribeDBInstances(
+		&rds.DescribeDBInstancesInput{
+			DBInstanceIdentifier: aws.String(i.OriginalInstanceName),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(output.DBInstances) == 0 {
+		return nil, fmt.Errorf("No such instance %s", i.OriginalInstanceName)
+	}
+	source := output.DBInstances[0]
+	earliest, latest, err := restoreWindow(source, svc)
+	if err != nil {
+		return nil, err
+	}
+	if !restoreTime.IsZero() &&
+		(restoreTime.Before(earliest) || restoreTime.After(latest)) {
+		return nil, fmt.Errorf(
+			"%s can only be restored between %s and %s",
+			i.OriginalInstanceName,
+			earliest.Format(RFC8601),
+			latest.Format(RFC8601),
+		)
+	}
+	if i.Engine == "" {
+		i.Engine = aws.StringValue(source.Engine)
+	}
+	err = i.validateRestore(
+		"instance",
+		aws.StringValue(source.Engine),
+		aws.Int64Value(source.AllocatedStorage),
+	)
+	if err != nil {
+		return nil, err
+	}
+	result = &rds.RestoreDBInstanceToPointInTimeInput{
+		DBInstanceClass:            &i.Type,
+		DBSubnetGroupName:          &i.SubnetGroupName,
+		Engine:                     aws.String(i.engine()),
+		MultiAZ:                    aws.Bool(i.MultiAZ),
+		SourceDBInstanceIdentifier: &i.OriginalInstanceName,
+		TargetDBInstanceIdentifier: &i.Identifier,
+	}
+	if restoreTime.IsZero() {
+		result.UseLatestRestorableTime = aws.Bool(true)
+	} else {
+		result.RestoreTime = aws.Time(restoreTime)
+	}
+	if i.OptionGroupName != "" {
+		result.OptionGroupName = aws.String(i.OptionGroupName)
+	}
+	if i.StorageType != "" {
+		result.StorageType = aws.String(i.StorageType)
+	}
+	if i.IOPS != 0 {
+		result.Iops = aws.Int64(i.IOPS)
 	}
 	if err = validate(result); err != nil {
 		return nil, err
@@ -132,6 +302,45 @@ func (i Instance) RestoreDBInput(
 	return result, nil
 }
 
+// restoreWindow returns the earliest and latest times source can be
+// restored to. RDS only reports the latest one for instances, so the
+// earliest is the creation of the oldest automated snapshot kept, or of
+// the instance if there is none yet.
+func restoreWindow(
+	source *rds.DBInstance,
+	svc rdsiface.RDSAPI,
+) (
+	earliest time.Time,
+	latest time.Time,
+	err error,
+) {
+	id := aws.StringValue(source.DBInstanceIdentifier)
+	if aws.Int64Value(source.BackupRetentionPeriod) == 0 ||
+		source.LatestRestorableTime == nil {
+		err = fmt.Errorf("%s has no automated backups to restore from", id)
+		return
+	}
+	latest = *source.LatestRestorableTime
+	earliest = aws.TimeValue(source.InstanceCreateTime)
+	snapshots, err := describeSnapshots(
+		&rds.DescribeDBSnapshotsInput{
+			DBInstanceIdentifier: source.DBInstanceIdentifier,
+			SnapshotType:         aws.String("automated"),
+		},
+		svc,
+	)
+	if err != nil {
+		return
+	}
+	for n := len(snapshots) - 1; n >= 0; n-- {
+		if snapshots[n].SnapshotCreateTime != nil {
+			earliest = *snapshots[n].SnapshotCreateTime
+			break
+		}
+	}
+	return
+}
+
 // addLastSnapshot adds the reference to the last available snapshot
 // of the target instance to this instance.
 func (i *Instance) addLastSnapshot(
@@ -3,7 +3,9 @@ package odin_test
 import(
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/rds"
 
 	"github.com/poka-yoke/spaceflight/internal/test/mocks"
@@ -286,3 +288,96 @@ func TestRestoreDBInput(t *testing.T) {
 		}
 	}
 }
+
+func TestPointInTimeRestoreDBInput(t *testing.T) {
+	production := *exampleInstance1
+	production.InstanceCreateTime = aws.Time(getTime("2015-01-01T00:00:00+00:00"))
+	production.LatestRestorableTime = aws.Time(getTime("2015-06-12T10:00:00+00:00"))
+	develop := *exampleInstance2
+	automated := *exampleSnapshot1
+	automated.SnapshotType = aws.String("automated")
+	manual := *exampleSnapshot1
+	manual.DBSnapshotIdentifier = aws.String("production-manual")
+	manual.SnapshotCreateTime = aws.Time(getTime("2015-05-11T22:00:00+00:00"))
+	manual.SnapshotType = aws.String("manual")
+	tt := []struct{
+		input odin.Instance
+		restoreTime string
+		err string
+	}{
+		// Underspecified options
+		{
+			input: odin.Instance{OriginalInstanceName: ""},
+			err: "Original Instance Name was empty",
+		},
+		// Non-existing instance
+		{
+			input: odin.Instance{OriginalInstanceName: "im-not-here"},
+			err: "DBInstanceNotFound: No such instance im-not-here",
+		},
+		// No automated backups
+		{
+			input: odin.Instance{OriginalInstanceName: "develop-rds"},
+			err: "develop-rds has no automated backups to restore from",
+		},
+		// Latest restorable time
+		{
+			input: odin.Instance{OriginalInstanceName: "production-rds"},
+		},
+		// Time within the window
+		{
+			input: odin.Instance{OriginalInstanceName: "production-rds"},
+			restoreTime: "2015-06-12T09:00:00+00:00",
+		},
+		// Time before the oldest automated snapshot
+		{
+			input: odin.Instance{OriginalInstanceName: "production-rds"},
+			restoreTime: "2015-06-01T00:00:00+00:00",
+			err: "production-rds can only be restored between 2015-06-11T22:00:00+00:00 and 2015-06-12T10:00:00+00:00",
+		},
+		// Time after the latest restorable time
+		{
+			input: odin.Instance{OriginalInstanceName: "production-rds"},
+			restoreTime: "2015-06-12T11:00:00+00:00",
+			err: "production-rds can only be restored between 2015-06-11T22:00:00+00:00 and 2015-06-12T10:00:00+00:00",
+		},
+		// Different engine
+		{
+			input: odin.Instance{
+				OriginalInstanceName: "production-rds",
+				Engine: "mysql",
+			},
+			err: "A postgres instance can't be restored as mysql",
+		},
+	}
+	svc := mocks.NewRDSClient()
+	svc.AddInstances([]*rds.DBInstance{&production, &develop})
+	svc.AddSnapshots([]*rds.DBSnapshot{&automated, &manual})
+	for _, tc := range tt {
+		var restoreTime time.Time
+		if tc.restoreTime != "" {
+			restoreTime = getTime(tc.restoreTime)
+		}
+		tc.input.Identifier = "production-restored"
+		tc.input.Type = "db.m4.large"
+		res, err := tc.input.PointInTimeRestoreDBInput(restoreTime, svc)
+		switch {
+		case tc.err != "" && (err == nil || err.Error() != tc.err):
+			t.Errorf("Expected: %s, but got %v", tc.err, err)
+		case tc.err == "" && err != nil:
+			t.Errorf("Unexpected error: %s", err)
+		case err != nil:
+		case restoreTime.IsZero() && !*res.UseLatestRestorableTime:
+			t.Errorf("Expected to restore to the latest restorable time")
+		case !restoreTime.IsZero() && !res.RestoreTime.Equal(restoreTime):
+			t.Errorf(
+				"Expected: %s, but got %s",
+				restoreTime,
+				*res.RestoreTime,
+			)
+		case *res.Engine != "postgres" ||
+			*res.SourceDBInstanceIdentifier != "production-rds":
+			t.Errorf("Unexpected input %s", res)
+		}
+	}
+}
@@ -52,16 +52,49 @@ func ListSnapshots(
 	if err != nil {
 		return
 	}
-	// Lambda function here implements reverse ordering based
-	// on snapshot's creation time, so first element is newer.
-	sort.Slice(
-		output.DBSnapshots,
+	result = output.DBSnapshots
+	sortSnapshots(result)
+	return
+}
+
+// describeSnapshots returns the snapshots matching input, across all
+// result pages, newest first.
+func describeSnapshots(
+	input *rds.DescribeDBSnapshotsInput,
+	svc rdsiface.RDSAPI,
+) (
+	result []*rds.DBSnapshot,
+	err error,
+) {
+	err = svc.DescribeDBSnapshotsPages(
+		input,
+		func(page *rds.DescribeDBSnapshotsOutput, lastPage bool) bool {
+			result = append(result, page.DBSnapshots...)
+			return true
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	sortSnapshots(result)
+	return
+}
+
+// sortSnapshots sorts snapshots by creation time in reverse order, so
+// first element is newer. Snapshots without creation time yet go last.
+func sortSnapshots(snapshots []*rds.DBSnapshot) {
+	sort.SliceStable(
+		snapshots,
 		func(i, j int) bool {
-			return output.DBSnapshots[i].SnapshotCreateTime.After(
-				*output.DBSnapshots[j].SnapshotCreateTime,
+			switch {
+			case snapshots[i].SnapshotCreateTime == nil:
+				return false
+			case snapshots[j].SnapshotCreateTime == nil:
+				return true
+			}
+			return snapshots[i].SnapshotCreateTime.After(
+				*snapshots[j].SnapshotCreateTime,
 			)
 		},
 	)
-	result = output.DBSnapshots
-	return
 }
@@ -184,6 +184,22 @@ var listSnapshotsCases = []listSnapshotsCase{
 		},
 		instanceID: "develop-rds",
 	},
+	// Snapshot without creation time
+	{
+		Case: test.Case{
+			Expected: []*rds.DBSnapshot{
+				exampleSnapshot3,
+				exampleSnapshot4,
+			},
+			ExpectedError: "",
+		},
+		name: "Snapshot being created goes last",
+		snapshots: []*rds.DBSnapshot{
+			exampleSnapshot4,
+			exampleSnapshot3,
+		},
+		instanceID: "develop-rds",
+	},
 }
 
 func TestListSnapshots(t *testing.T) {
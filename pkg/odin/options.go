@@ -0,0 +1,240 @@
+package odin
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+)
+
+// DefaultEngine is the engine of instances not specifying any.
+const DefaultEngine = "postgres"
+
+var engines = []string{
+	"aurora",
+	"aurora-mysql",
+	"aurora-postgresql",
+	"mariadb",
+	"mysql",
+	"oracle-ee",
+	"oracle-se",
+	"oracle-se1",
+	"oracle-se2",
+	"postgres",
+	"sqlserver-ee",
+	"sqlserver-ex",
+	"sqlserver-se",
+	"sqlserver-web",
+}
+
+var storageTypes = []string{
+	"standard",
+	"gp2",
+	"io1",
+}
+
+// unencryptedClasses are the instance class prefixes which don't
+// support storage encryption.
+var unencryptedClasses = []string{
+	"db.m1.",
+	"db.m2.",
+	"db.t1.",
+	"db.t2.micro",
+}
+
+func contains(list []string, s string) bool {
+	for _, elem := range list {
+		if elem == s {
+			return true
+		}
+	}
+	return false
+}
+
+// validateOptions checks the combination of options of the instance,
+// with size GB of storage, before sending it to RDS.
+func (i Instance) validateOptions(size int64) error {
+	engine := i.engine()
+	switch {
+	case !contains(engines, engine):
+		return fmt.Errorf("Unknown engine %s", engine)
+	case strings.HasPrefix(engine, "aurora"):
+		return fmt.Errorf("%s instances must be created in a cluster", engine)
+	case i.StorageType != "" && !contains(storageTypes, i.StorageType):
+		return fmt.Errorf("Unknown storage type %s", i.StorageType)
+	case i.StorageType == "io1" && i.IOPS == 0:
+		return fmt.Errorf("io1 storage requires IOPS")
+	case i.IOPS != 0 && i.StorageType != "io1":
+		return fmt.Errorf("IOPS can only be provisioned with io1 storage")
+	case i.IOPS < 0 || i.IOPS != 0 && i.IOPS < 1000:
+		return fmt.Errorf("IOPS must be at least 1000")
+	case i.IOPS != 0 && size > 0 && (i.IOPS < size || i.IOPS > 50*size):
+		return fmt.Errorf(
+			"IOPS must be between 1 and 50 times the %d GB of storage",
+			size,
+		)
+	case i.KMSKeyID != "" && !i.Encrypted:
+		return fmt.Errorf("A KMS key can only be used with encryption")
+	case i.MultiAZ && (engine == "sqlserver-ex" || engine == "sqlserver-web"):
+		return fmt.Errorf("Multi-AZ is not available for %s", engine)
+	case i.BackupRetentionPeriod < 0 || i.BackupRetentionPeriod > 35:
+		return fmt.Errorf("Backup retention must be at most 35 days")
+	}
+	if i.Encrypted {
+		for _, prefix := range unencryptedClasses {
+			if strings.HasPrefix(i.Type, prefix) {
+				return fmt.Errorf(
+					"Encryption is not available for %s instances",
+					i.Type,
+				)
+			}
+		}
+	}
+	if i.MaintenanceWindow != "" {
+		return validateWindow(i.MaintenanceWindow)
+	}
+	return nil
+}
+
+// validateRestore checks the options of the instance can be used to
+// restore it from source, either a snapshot or an instance, of the
+// original engine and size GB of storage.
+func (i Instance) validateRestore(source, original string, size int64) error {
+	switch {
+	case i.Encrypted || i.KMSKeyID != "":
+		return fmt.Errorf("Encryption is inherited from the %s", source)
+	case i.EngineVersion != "":
+		return fmt.Errorf("Engine version is inherited from the %s", source)
+	}
+	family := func(engine string) string {
+		return strings.SplitN(engine, "-", 2)[0]
+	}
+	if original != "" && family(original) != family(i.engine()) {
+		return fmt.Errorf(
+			"A %s %s can't be restored as %s",
+			original,
+			source,
+			i.engine(),
+		)
+	}
+	return i.validateOptions(size)
+}
+
+var days = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+var windowFormat = regexp.MustCompile(
+	`^([a-z]{3}):(\d{2}):(\d{2})-([a-z]{3}):(\d{2}):(\d{2})$`,
+)
+
+// validateWindow checks window is a valid maintenance window, in
+// ddd:hh24:mi-ddd:hh24:mi format, of at least 30 minutes.
+func validateWindow(window string) error {
+	invalid := fmt.Errorf(
+		"%s is not a valid maintenance window, like sun:05:00-sun:06:00",
+		window,
+	)
+	parts := windowFormat.FindStringSubmatch(strings.ToLower(window))
+	if parts == nil {
+		return invalid
+	}
+	minute := func(day, hour, min string) int {
+		d := -1
+		for n, name := range days {
+			if name == day {
+				d = n
+			}
+		}
+		h, _ := strconv.Atoi(hour)
+		m, _ := strconv.Atoi(min)
+		if d < 0 || h > 23 || m > 59 {
+			return -1
+		}
+		return (d*24+h)*60 + m
+	}
+	start := minute(parts[1], parts[2], parts[3])
+	end := minute(parts[4], parts[5], parts[6])
+	if start < 0 || end < 0 {
+		return invalid
+	}
+	week := 7 * 24 * 60
+	if (end-start+week)%week < 30 {
+		return fmt.Errorf("Maintenance window %s is shorter than 30 minutes", window)
+	}
+	return nil
+}
+
+// CheckEngine checks against RDS that the engine version of the
+// instance exists, and that its parameter and option groups belong to
+// that engine version.
+func (i Instance) CheckEngine(svc rdsiface.RDSAPI) error {
+	engine := i.engine()
+	input := &rds.DescribeDBEngineVersionsInput{
+		Engine: aws.String(engine),
+	}
+	if i.EngineVersion != "" {
+		input.EngineVersion = aws.String(i.EngineVersion)
+	} else {
+		input.DefaultOnly = aws.Bool(true)
+	}
+	versions, err := svc.DescribeDBEngineVersions(input)
+	if err != nil {
+		return err
+	}
+	if len(versions.DBEngineVersions) == 0 {
+		return fmt.Errorf("Unknown %s version %s", engine, i.EngineVersion)
+	}
+	version := versions.DBEngineVersions[0]
+	if i.ParameterGroupName != "" {
+		groups, err := svc.DescribeDBParameterGroups(
+			&rds.DescribeDBParameterGroupsInput{
+				DBParameterGroupName: aws.String(i.ParameterGroupName),
+			},
+		)
+		if err != nil {
+			return err
+		}
+		for _, group := range groups.DBParameterGroups {
+			family := aws.StringValue(group.DBParameterGroupFamily)
+			if family != aws.StringValue(version.DBParameterGroupFamily) {
+				return fmt.Errorf(
+					"Parameter group %s is for %s, not %s %s",
+					i.ParameterGroupName,
+					family,
+					engine,
+					aws.StringValue(version.EngineVersion),
+				)
+			}
+		}
+	}
+	if i.OptionGroupName != "" {
+		groups, err := svc.DescribeOptionGroups(
+			&rds.DescribeOptionGroupsInput{
+				OptionGroupName: aws.String(i.OptionGroupName),
+			},
+		)
+		if err != nil {
+			return err
+		}
+		for _, group := range groups.OptionGroupsList {
+			if aws.StringValue(group.EngineName) != engine ||
+				!strings.HasPrefix(
+					aws.StringValue(version.EngineVersion),
+					aws.StringValue(group.MajorEngineVersion),
+				) {
+				return fmt.Errorf(
+					"Option group %s is for %s %s, not %s %s",
+					i.OptionGroupName,
+					aws.StringValue(group.EngineName),
+					aws.StringValue(group.MajorEngineVersion),
+					engine,
+					aws.StringValue(version.EngineVersion),
+				)
+			}
+		}
+	}
+	return nil
+}
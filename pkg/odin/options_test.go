@@ -0,0 +1,321 @@
+package odin_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+
+	"github.com/poka-yoke/spaceflight/internal/test"
+	"github.com/poka-yoke/spaceflight/internal/test/mocks"
+	"github.com/poka-yoke/spaceflight/pkg/odin"
+)
+
+var validateOptionsCases = []struct {
+	test.Case
+	name     string
+	instance odin.Instance
+}{
+	{
+		name:     "Defaults",
+		instance: odin.Instance{},
+	},
+	{
+		name: "All options",
+		instance: odin.Instance{
+			Engine:                "mysql",
+			EngineVersion:         "5.7.21",
+			ParameterGroupName:    "mysql57",
+			OptionGroupName:       "mysql57",
+			StorageType:           "io1",
+			IOPS:                  1000,
+			MultiAZ:               true,
+			Encrypted:             true,
+			KMSKeyID:              "alias/rds",
+			BackupRetentionPeriod: 7,
+			MaintenanceWindow:     "Sun:05:00-Sun:06:00",
+		},
+	},
+	{
+		Case:     test.Case{ExpectedError: "Unknown engine mongodb"},
+		name:     "Unknown engine",
+		instance: odin.Instance{Engine: "mongodb"},
+	},
+	{
+		Case: test.Case{
+			ExpectedError: "aurora-mysql instances must be created in a cluster",
+		},
+		name:     "Aurora",
+		instance: odin.Instance{Engine: "aurora-mysql"},
+	},
+	{
+		Case:     test.Case{ExpectedError: "Unknown storage type ssd"},
+		name:     "Unknown storage type",
+		instance: odin.Instance{StorageType: "ssd"},
+	},
+	{
+		Case:     test.Case{ExpectedError: "io1 storage requires IOPS"},
+		name:     "io1 without IOPS",
+		instance: odin.Instance{StorageType: "io1"},
+	},
+	{
+		Case: test.Case{
+			ExpectedError: "IOPS can only be provisioned with io1 storage",
+		},
+		name:     "IOPS without io1",
+		instance: odin.Instance{StorageType: "gp2", IOPS: 1000},
+	},
+	{
+		Case:     test.Case{ExpectedError: "IOPS must be at least 1000"},
+		name:     "Too few IOPS",
+		instance: odin.Instance{StorageType: "io1", IOPS: 500},
+	},
+	{
+		Case: test.Case{
+			ExpectedError: "IOPS must be between 1 and 50 times the 100 GB of storage",
+		},
+		name:     "Too many IOPS",
+		instance: odin.Instance{Size: 100, StorageType: "io1", IOPS: 6000},
+	},
+	{
+		Case: test.Case{
+			ExpectedError: "A KMS key can only be used with encryption",
+		},
+		name:     "KMS key without encryption",
+		instance: odin.Instance{KMSKeyID: "alias/rds"},
+	},
+	{
+		Case: test.Case{
+			ExpectedError: "Multi-AZ is not available for sqlserver-ex",
+		},
+		name:     "Multi-AZ SQL Server Express",
+		instance: odin.Instance{Engine: "sqlserver-ex", MultiAZ: true},
+	},
+	{
+		Case: test.Case{
+			ExpectedError: "Backup retention must be at most 35 days",
+		},
+		name:     "Long backup retention",
+		instance: odin.Instance{BackupRetentionPeriod: 40},
+	},
+	{
+		Case: test.Case{
+			ExpectedError: "Maintenance window sun:05:00-sun:05:15 is shorter than 30 minutes",
+		},
+		name:     "Short maintenance window",
+		instance: odin.Instance{MaintenanceWindow: "sun:05:00-sun:05:15"},
+	},
+	{
+		name:     "Maintenance window across weeks",
+		instance: odin.Instance{MaintenanceWindow: "sun:23:45-mon:00:15"},
+	},
+	{
+		Case: test.Case{
+			ExpectedError: "sun:25:00-sun:26:00 is not a valid maintenance window, like sun:05:00-sun:06:00",
+		},
+		name:     "Invalid maintenance window",
+		instance: odin.Instance{MaintenanceWindow: "sun:25:00-sun:26:00"},
+	},
+}
+
+func TestCreateDBInputOptions(t *testing.T) {
+	for _, tc := range validateOptionsCases {
+		t.Run(
+			tc.name,
+			func(t *testing.T) {
+				_, err := tc.instance.CreateDBInput()
+				tc.Check(nil, err, t)
+			},
+		)
+	}
+}
+
+func TestCreateDBInputEncryptionClass(t *testing.T) {
+	i := odin.Instance{Encrypted: true, Type: "db.t2.micro"}
+	_, err := i.CreateDBInput()
+	tc := test.Case{
+		ExpectedError: "Encryption is not available for db.t2.micro instances",
+	}
+	tc.Check(nil, err, t)
+}
+
+func TestRestoreDBInputOptions(t *testing.T) {
+	tt := []struct {
+		test.Case
+		name     string
+		original string
+		instance odin.Instance
+	}{
+		{
+			name:     "Multi-AZ with provisioned IOPS",
+			instance: odin.Instance{MultiAZ: true, StorageType: "io1", IOPS: 1000},
+		},
+		{
+			Case: test.Case{
+				ExpectedError: "Encryption is inherited from the snapshot",
+			},
+			name:     "Encryption",
+			instance: odin.Instance{Encrypted: true},
+		},
+		{
+			Case: test.Case{
+				ExpectedError: "Engine version is inherited from the snapshot",
+			},
+			name:     "Engine version",
+			instance: odin.Instance{EngineVersion: "9.6.6"},
+		},
+		{
+			Case: test.Case{
+				ExpectedError: "A postgres snapshot can't be restored as mysql",
+			},
+			name:     "Different engine",
+			instance: odin.Instance{Engine: "mysql"},
+		},
+		{
+			Case: test.Case{
+				ExpectedError: "IOPS must be between 1 and 50 times the 10 GB of storage",
+			},
+			name:     "Too many IOPS for the snapshot",
+			original: "develop-rds",
+			instance: odin.Instance{StorageType: "io1", IOPS: 1000},
+		},
+	}
+	snapshot := *exampleSnapshot1
+	snapshot.Engine = aws.String("postgres")
+	snapshot.AllocatedStorage = aws.Int64(100)
+	small := *exampleSnapshot2
+	small.Engine = aws.String("postgres")
+	svc := mocks.NewRDSClient()
+	svc.AddSnapshots([]*rds.DBSnapshot{&snapshot, &small})
+	for _, tc := range tt {
+		t.Run(
+			tc.name,
+			func(t *testing.T) {
+				instance := tc.instance
+				instance.OriginalInstanceName = "production-rds"
+				if tc.original != "" {
+					instance.OriginalInstanceName = tc.original
+				}
+				_, err := instance.RestoreDBInput(svc)
+				tc.Check(nil, err, t)
+			},
+		)
+	}
+}
+
+// engineRDSClient adds engine versions, parameter and option groups to
+// the RDS mock.
+type engineRDSClient struct {
+	*mocks.RDSClient
+}
+
+func (m engineRDSClient) DescribeDBEngineVersions(
+	input *rds.DescribeDBEngineVersionsInput,
+) (*rds.DescribeDBEngineVersionsOutput, error) {
+	versions := []*rds.DBEngineVersion{
+		{
+			Engine:                 aws.String("postgres"),
+			EngineVersion:          aws.String("9.6.6"),
+			DBParameterGroupFamily: aws.String("postgres9.6"),
+		},
+		{
+			Engine:                 aws.String("postgres"),
+			EngineVersion:          aws.String("10.3"),
+			DBParameterGroupFamily: aws.String("postgres10"),
+		},
+	}
+	out := &rds.DescribeDBEngineVersionsOutput{}
+	for _, version := range versions {
+		if aws.StringValue(input.Engine) != *version.Engine {
+			continue
+		}
+		if input.EngineVersion != nil &&
+			*input.EngineVersion != *version.EngineVersion {
+			continue
+		}
+		out.DBEngineVersions = append(out.DBEngineVersions, version)
+	}
+	if aws.BoolValue(input.DefaultOnly) && len(out.DBEngineVersions) > 0 {
+		out.DBEngineVersions = out.DBEngineVersions[len(out.DBEngineVersions)-1:]
+	}
+	return out, nil
+}
+
+func (m engineRDSClient) DescribeDBParameterGroups(
+	input *rds.DescribeDBParameterGroupsInput,
+) (*rds.DescribeDBParameterGroupsOutput, error) {
+	return &rds.DescribeDBParameterGroupsOutput{
+		DBParameterGroups: []*rds.DBParameterGroup{
+			{
+				DBParameterGroupName:   input.DBParameterGroupName,
+				DBParameterGroupFamily: aws.String("postgres9.6"),
+			},
+		},
+	}, nil
+}
+
+func (m engineRDSClient) DescribeOptionGroups(
+	input *rds.DescribeOptionGroupsInput,
+) (*rds.DescribeOptionGroupsOutput, error) {
+	return &rds.DescribeOptionGroupsOutput{
+		OptionGroupsList: []*rds.OptionGroup{
+			{
+				OptionGroupName:    input.OptionGroupName,
+				EngineName:         aws.String("postgres"),
+				MajorEngineVersion: aws.String("9.6"),
+			},
+		},
+	}, nil
+}
+
+func TestCheckEngine(t *testing.T) {
+	tt := []struct {
+		test.Case
+		name     string
+		instance odin.Instance
+	}{
+		{
+			name:     "Default version",
+			instance: odin.Instance{},
+		},
+		{
+			name: "Matching groups",
+			instance: odin.Instance{
+				EngineVersion:      "9.6.6",
+				ParameterGroupName: "pg96",
+				OptionGroupName:    "pg96",
+			},
+		},
+		{
+			Case:     test.Case{ExpectedError: "Unknown postgres version 9.3.1"},
+			name:     "Unknown version",
+			instance: odin.Instance{EngineVersion: "9.3.1"},
+		},
+		{
+			Case: test.Case{
+				ExpectedError: "Parameter group pg96 is for postgres9.6, not postgres 10.3",
+			},
+			name:     "Parameter group for another version",
+			instance: odin.Instance{ParameterGroupName: "pg96"},
+		},
+		{
+			Case: test.Case{
+				ExpectedError: "Option group pg96 is for postgres 9.6, not postgres 10.3",
+			},
+			name: "Option group for another version",
+			instance: odin.Instance{
+				EngineVersion:   "10.3",
+				OptionGroupName: "pg96",
+			},
+		},
+	}
+	svc := engineRDSClient{mocks.NewRDSClient()}
+	for _, tc := range tt {
+		t.Run(
+			tc.name,
+			func(t *testing.T) {
+				tc.Check(nil, tc.instance.CheckEngine(svc), t)
+			},
+		)
+	}
+}
@@ -0,0 +1,146 @@
+package odin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+)
+
+// RetentionPolicy is a grandfather-father-son retention policy for
+// snapshots. It keeps the newest snapshot of each of the latest Daily
+// days, Weekly ISO weeks and Monthly months having snapshots.
+type RetentionPolicy struct {
+	Daily   int
+	Weekly  int
+	Monthly int
+}
+
+// SnapshotPlan is the decision taken for a snapshot by a retention
+// policy. Reasons lists the retention buckets keeping it.
+type SnapshotPlan struct {
+	Snapshot *rds.DBSnapshot
+	Keep     bool
+	Reasons  []string
+}
+
+func (p SnapshotPlan) String() string {
+	action := "delete"
+	if p.Keep {
+		action = "keep"
+	}
+	out := fmt.Sprintf(
+		"%s %s %s",
+		action,
+		aws.StringValue(p.Snapshot.DBSnapshotIdentifier),
+		p.Snapshot.SnapshotCreateTime.UTC().Format(RFC8601),
+	)
+	if len(p.Reasons) > 0 {
+		out = fmt.Sprintf("%s %v", out, p.Reasons)
+	}
+	return out
+}
+
+// Plan classifies snapshots, newest first, into the retention buckets
+// of the policy. Only available manual snapshots are classified, as
+// RDS expires automated ones by itself.
+func (p RetentionPolicy) Plan(snapshots []*rds.DBSnapshot) ([]SnapshotPlan, error) {
+	if p.Daily <= 0 && p.Weekly <= 0 && p.Monthly <= 0 {
+		return nil, fmt.Errorf("Retention policy would keep no snapshots")
+	}
+	result := []SnapshotPlan{}
+	for _, snapshot := range snapshots {
+		if aws.StringValue(snapshot.SnapshotType) != "manual" ||
+			aws.StringValue(snapshot.Status) != "available" ||
+			snapshot.SnapshotCreateTime == nil {
+			continue
+		}
+		result = append(result, SnapshotPlan{Snapshot: snapshot})
+	}
+	buckets := []struct {
+		name string
+		keep int
+		key  func(time.Time) string
+	}{
+		{
+			name: "daily",
+			keep: p.Daily,
+			key: func(t time.Time) string {
+				return t.Format("2006-01-02")
+			},
+		},
+		{
+			name: "weekly",
+			keep: p.Weekly,
+			key: func(t time.Time) string {
+				year, week := t.ISOWeek()
+				return fmt.Sprintf("%d-W%02d", year, week)
+			},
+		},
+		{
+			name: "monthly",
+			keep: p.Monthly,
+			key: func(t time.Time) string {
+				return t.Format("2006-01")
+			},
+		},
+	}
+	for _, bucket := range buckets {
+		seen := make(map[string]bool)
+		for n := range result {
+			key := bucket.key(result[n].Snapshot.SnapshotCreateTime.UTC())
+			if seen[key] || len(seen) >= bucket.keep {
+				continue
+			}
+			seen[key] = true
+			result[n].Keep = true
+			result[n].Reasons = append(result[n].Reasons, bucket.name)
+		}
+	}
+	return result, nil
+}
+
+// PlanPrune returns the plan of the policy for the snapshots of the
+// instance with the id.
+func PlanPrune(
+	id string,
+	policy RetentionPolicy,
+	svc rdsiface.RDSAPI,
+) ([]SnapshotPlan, error) {
+	if id == "" {
+		return nil, fmt.Errorf("Instance to prune snapshots of was empty")
+	}
+	snapshots, err := describeSnapshots(
+		&rds.DescribeDBSnapshotsInput{
+			DBInstanceIdentifier: aws.String(id),
+			SnapshotType:         aws.String("manual"),
+		},
+		svc,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return policy.Plan(snapshots)
+}
+
+// Prune deletes the snapshots not kept by plan, returning the amount
+// deleted.
+func Prune(plan []SnapshotPlan, svc rdsiface.RDSAPI) (deleted int, err error) {
+	for _, p := range plan {
+		if p.Keep {
+			continue
+		}
+		_, err = svc.DeleteDBSnapshot(
+			&rds.DeleteDBSnapshotInput{
+				DBSnapshotIdentifier: p.Snapshot.DBSnapshotIdentifier,
+			},
+		)
+		if err != nil {
+			return
+		}
+		deleted++
+	}
+	return
+}
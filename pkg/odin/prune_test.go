@@ -0,0 +1,159 @@
+package odin_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+
+	"github.com/poka-yoke/spaceflight/internal/test/mocks"
+	"github.com/poka-yoke/spaceflight/pkg/odin"
+)
+
+func TestRetentionPolicyPlan(t *testing.T) {
+	expected := []string{
+		"keep s0 2018-06-15T12:00:00+00:00 [daily weekly monthly]",
+		"delete s1 2018-06-15T06:00:00+00:00",
+		"keep s2 2018-06-14T06:00:00+00:00 [daily]",
+		"keep s3 2018-06-10T06:00:00+00:00 [weekly]",
+		"delete s4 2018-06-04T06:00:00+00:00",
+		"keep s5 2018-05-31T06:00:00+00:00 [monthly]",
+		"delete s6 2018-05-01T06:00:00+00:00",
+		"delete s7 2018-04-15T06:00:00+00:00",
+	}
+	svc := mocks.NewRDSClient()
+	svc.AddSnapshots([]*rds.DBSnapshot{
+		{
+			DBInstanceIdentifier: exampleSnapshot1DBID,
+			DBSnapshotIdentifier: aws.String("s0"),
+			SnapshotCreateTime:   aws.Time(getTime("2018-06-15T12:00:00+00:00")),
+			SnapshotType:         aws.String("manual"),
+			Status:               aws.String("available"),
+		},
+		{
+			DBInstanceIdentifier: exampleSnapshot1DBID,
+			DBSnapshotIdentifier: aws.String("s1"),
+			SnapshotCreateTime:   aws.Time(getTime("2018-06-15T06:00:00+00:00")),
+			SnapshotType:         aws.String("manual"),
+			Status:               aws.String("available"),
+		},
+		{
+			DBInstanceIdentifier: exampleSnapshot1DBID,
+			DBSnapshotIdentifier: aws.String("s2"),
+			SnapshotCreateTime:   aws.Time(getTime("2018-06-14T06:00:00+00:00")),
+			SnapshotType:         aws.String("manual"),
+			Status:               aws.String("available"),
+		},
+		{
+			DBInstanceIdentifier: exampleSnapshot1DBID,
+			DBSnapshotIdentifier: aws.String("s3"),
+			SnapshotCreateTime:   aws.Time(getTime("2018-06-10T06:00:00+00:00")),
+			SnapshotType:         aws.String("manual"),
+			Status:               aws.String("available"),
+		},
+		{
+			DBInstanceIdentifier: exampleSnapshot1DBID,
+			DBSnapshotIdentifier: aws.String("s4"),
+			SnapshotCreateTime:   aws.Time(getTime("2018-06-04T06:00:00+00:00")),
+			SnapshotType:         aws.String("manual"),
+			Status:               aws.String("available"),
+		},
+		{
+			DBInstanceIdentifier: exampleSnapshot1DBID,
+			DBSnapshotIdentifier: aws.String("s5"),
+			SnapshotCreateTime:   aws.Time(getTime("2018-05-31T06:00:00+00:00")),
+			SnapshotType:         aws.String("manual"),
+			Status:               aws.String("available"),
+		},
+		{
+			DBInstanceIdentifier: exampleSnapshot1DBID,
+			DBSnapshotIdentifier: aws.String("s6"),
+			SnapshotCreateTime:   aws.Time(getTime("2018-05-01T06:00:00+00:00")),
+			SnapshotType:         aws.String("manual"),
+			Status:               aws.String("available"),
+		},
+		{
+			DBInstanceIdentifier: exampleSnapshot1DBID,
+			DBSnapshotIdentifier: aws.String("s7"),
+			SnapshotCreateTime:   aws.Time(getTime("2018-04-15T06:00:00+00:00")),
+			SnapshotType:         aws.String("manual"),
+			Status:               aws.String("available"),
+		},
+		{
+			DBInstanceIdentifier: exampleSnapshot1DBID,
+			DBSnapshotIdentifier: aws.String("automated"),
+			SnapshotCreateTime:   aws.Time(getTime("2018-04-01T06:00:00+00:00")),
+			SnapshotType:         aws.String("automated"),
+			Status:               aws.String("available"),
+		},
+		{
+			DBInstanceIdentifier: exampleSnapshot1DBID,
+			DBSnapshotIdentifier: aws.String("creating"),
+			SnapshotCreateTime:   aws.Time(getTime("2018-03-01T06:00:00+00:00")),
+			SnapshotType:         aws.String("manual"),
+			Status:               aws.String("creating"),
+		},
+	})
+	plan, err := odin.PlanPrune(
+		"production-rds",
+		odin.RetentionPolicy{Daily: 2, Weekly: 2, Monthly: 2},
+		svc,
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	var actual []string
+	for _, p := range plan {
+		actual = append(actual, p.String())
+	}
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf(
+			"Unexpected plan:\n%s\nExpected:\n%s",
+			strings.Join(actual, "\n"),
+			strings.Join(expected, "\n"),
+		)
+	}
+
+	deleted, err := odin.Prune(plan, svc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if deleted != 4 {
+		t.Errorf("Expected 4 snapshots deleted, got %d", deleted)
+	}
+	remaining, _ := odin.ListSnapshots("production-rds", svc)
+	var ids []string
+	for _, snapshot := range remaining {
+		ids = append(ids, *snapshot.DBSnapshotIdentifier)
+	}
+	if strings.Join(ids, " ") != "s0 s2 s3 s5 automated creating" {
+		t.Errorf("Unexpected snapshots left: %v", ids)
+	}
+}
+
+func TestRetentionPolicyPlanErrors(t *testing.T) {
+	svc := mocks.NewRDSClient()
+	svc.AddSnapshots([]*rds.DBSnapshot{exampleSnapshot1})
+	tt := []struct {
+		id     string
+		policy odin.RetentionPolicy
+		err    string
+	}{
+		{
+			id:     "",
+			policy: odin.RetentionPolicy{Daily: 1},
+			err:    "Instance to prune snapshots of was empty",
+		},
+		{
+			id:  "production-rds",
+			err: "Retention policy would keep no snapshots",
+		},
+	}
+	for _, tc := range tt {
+		_, err := odin.PlanPrune(tc.id, tc.policy, svc)
+		if err == nil || err.Error() != tc.err {
+			t.Errorf("Expected: %s, but got %v", tc.err, err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package odin
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+)
+
+// ConnectionString returns the connection string to the database of
+// instance, as user, for database/sql. Only PostgreSQL is supported.
+func ConnectionString(
+	instance *rds.DBInstance,
+	user string,
+	password string,
+	database string,
+) (string, error) {
+	id := aws.StringValue(instance.DBInstanceIdentifier)
+	if engine := aws.StringValue(instance.Engine); engine != "postgres" {
+		return "", fmt.Errorf("%s is %s, only postgres can be sanitized", id, engine)
+	}
+	if instance.Endpoint == nil {
+		return "", fmt.Errorf("%s has no endpoint yet", id)
+	}
+	connection := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(user, password),
+		Host: fmt.Sprintf(
+			"%s:%d",
+			aws.StringValue(instance.Endpoint.Address),
+			aws.Int64Value(instance.Endpoint.Port),
+		),
+		Path:     "/" + database,
+		RawQuery: "sslmode=require",
+	}
+	return connection.String(), nil
+}
+
+// Sanitize runs script in a transaction in db, so it is either fully
+// applied or not at all.
+func Sanitize(db *sql.DB, script string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(script); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,320 @@
+package odin
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+)
+
+// describeInstance returns the instance with the id, or nil if there
+// is no such instance.
+func describeInstance(id string, svc rdsiface.RDSAPI) (*rds.DBInstance, error) {
+	output, err := svc.DescribeDBInstances(
+		&rds.DescribeDBInstancesInput{
+			DBInstanceIdentifier: aws.String(id),
+		},
+	)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok &&
+			aerr.Code() == rds.ErrCodeDBInstanceNotFoundFault {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(output.DBInstances) == 0 {
+		return nil, nil
+	}
+	return output.DBInstances[0], nil
+}
+
+// statusOf returns the status of instance, or an empty string if nil.
+func statusOf(instance *rds.DBInstance) string {
+	if instance == nil {
+		return ""
+	}
+	return aws.StringValue(instance.DBInstanceStatus)
+}
+
+// instanceStatus returns the status of the instance with the id, or
+// an empty string if there is no such instance.
+func instanceStatus(id string, svc rdsiface.RDSAPI) (string, error) {
+	instance, err := describeInstance(id, svc)
+	return statusOf(instance), err
+}
+
+// WaitTimeout is how long waiting for an instance lasts before giving
+// up.
+var WaitTimeout = time.Hour
+
+// WaitForStatus polls the instance with the id every interval until it
+// is in status. An empty status waits until the instance is gone.
+// Throttling and other transient errors are retried until WaitTimeout.
+func WaitForStatus(
+	id string,
+	status string,
+	svc rdsiface.RDSAPI,
+	interval time.Duration,
+) error {
+	return waitFor(
+		id,
+		func(instance *rds.DBInstance) bool {
+			return statusOf(instance) == status
+		},
+		svc,
+		interval,
+	)
+}
+
+// WaitForModification polls the instance with the id every interval
+// until the modification just requested shows up, by leaving the
+// available status or having nothing pending, and then until it is
+// available again. Right after being modified, instances can still
+// report being available with the old settings.
+func WaitForModification(
+	id string,
+	svc rdsiface.RDSAPI,
+	interval time.Duration,
+) error {
+	err := waitFor(
+		id,
+		func(instance *rds.DBInstance) bool {
+			return statusOf(instance) != "available" || !pending(instance)
+		},
+		svc,
+		interval,
+	)
+	if err != nil {
+		return err
+	}
+	return WaitForStatus(id, "available", svc, interval)
+}
+
+// pending returns whether instance has modifications not applied yet,
+// including changes to its VPC security groups.
+func pending(instance *rds.DBInstance) bool {
+	if instance.PendingModifiedValues != nil &&
+		*instance.PendingModifiedValues != (rds.PendingModifiedValues{}) {
+		return true
+	}
+	for _, sg := range instance.VpcSecurityGroups {
+		if aws.StringValue(sg.Status) != "active" {
+			return true
+		}
+	}
+	return false
+}
+
+// waitFor polls the instance with the id every interval until done
+// returns true for it, which gets nil if the instance doesn't exist.
+// Throttling and other transient errors are retried until WaitTimeout.
+func waitFor(
+	id string,
+	done func(*rds.DBInstance) bool,
+	svc rdsiface.RDSAPI,
+	interval time.Duration,
+) error {
+	deadline := time.Now().Add(WaitTimeout)
+	for {
+		instance, err := describeInstance(id, svc)
+		switch {
+		case err != nil &&
+			!request.IsErrorThrottle(err) &&
+			!request.IsErrorRetryable(err):
+			return err
+		case err == nil && done(instance):
+			return nil
+		case statusOf(instance) == "failed":
+			return fmt.Errorf("%s instance failed", id)
+		case time.Now().After(deadline):
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("Timed out waiting for %s instance", id)
+		}
+		// This is to avoid AWS API rate throttling.
+		time.Sleep(interval)
+	}
+}
+
+// RenameInstance renames the instance with the id to newID, waiting
+// until it is available under the new name.
+func RenameInstance(
+	id string,
+	newID string,
+	svc rdsiface.RDSAPI,
+	interval time.Duration,
+) error {
+	input := &rds.ModifyDBInstanceInput{
+		ApplyImmediately:        aws.Bool(true),
+		DBInstanceIdentifier:    aws.String(id),
+		NewDBInstanceIdentifier: aws.String(newID),
+	}
+	if err := validate(input); err != nil {
+		return err
+	}
+	if _, err := svc.ModifyDBInstance(input); err != nil {
+		return err
+	}
+	return WaitForStatus(newID, "available", svc, interval)
+}
+
+// renameInstances renames instances in order, each rename being a pair
+// of the current and new identifiers. If any rename fails, the ones
+// done are undone, in reverse order.
+func renameInstances(
+	renames [][2]string,
+	svc rdsiface.RDSAPI,
+	interval time.Duration,
+) error {
+	for n, rename := range renames {
+		err := RenameInstance(rename[0], rename[1], svc, interval)
+		if err == nil {
+			continue
+		}
+		return undoRenames(renames[:n+1], err, svc, interval)
+	}
+	return nil
+}
+
+// undoRenames undoes, in reverse order, the renames which happened,
+// including the last one, which failed, but may have been applied, or
+// be still in progress. It returns err, or the error undoing them.
+func undoRenames(
+	renames [][2]string,
+	err error,
+	svc rdsiface.RDSAPI,
+	interval time.Duration,
+) error {
+	for m := len(renames) - 1; m >= 0; m-- {
+		undo := renames[m]
+		done, rerr := renamed(undo[0], undo[1], svc)
+		if rerr == nil && done {
+			rerr = WaitForStatus(undo[1], "available", svc, interval)
+		}
+		if rerr == nil && done {
+			rerr = RenameInstance(undo[1], undo[0], svc, interval)
+		}
+		if rerr != nil {
+			return fmt.Errorf(
+				"%s, and renaming %s back to %s failed too: %s",
+				err,
+				undo[1],
+				undo[0],
+				rerr,
+			)
+		}
+	}
+	return err
+}
+
+// renamed returns whether the instance with the id was renamed to
+// newID, which is when the former is gone and the latter exists.
+func renamed(id string, newID string, svc rdsiface.RDSAPI) (bool, error) {
+	status, err := instanceStatus(id, svc)
+	if err != nil || status != "" {
+		return false, err
+	}
+	status, err = instanceStatus(newID, svc)
+	return status != "", err
+}
+
+// MaxIdentifierLength is the longest identifier RDS accepts for
+// instances.
+const MaxIdentifierLength = 63
+
+// SuffixedIdentifier returns id followed by a hyphen and suffix,
+// shortening id for the result to fit in MaxIdentifierLength.
+func SuffixedIdentifier(id string, suffix string) string {
+	if max := MaxIdentifierLength - len(suffix) - 1; len(id) > max {
+		// Identifiers can't have two hyphens in a row.
+		id = strings.TrimRight(id[:max], "-")
+	}
+	return id + "-" + suffix
+}
+
+// checkIdentifiers returns an error if any of ids is too long for RDS,
+// so it is found before renaming anything.
+func checkIdentifiers(ids ...string) error {
+	for _, id := range ids {
+		if len(id) > MaxIdentifierLength {
+			return fmt.Errorf(
+				"%s is longer than %d characters",
+				id,
+				MaxIdentifierLength,
+			)
+		}
+	}
+	return nil
+}
+
+// ReplaceInstance replaces the instance with the id by replacement,
+// renaming the former to retired, if it exists, and then replacement
+// to id. If the second rename fails, the first is undone. It returns
+// whether there was an instance to retire.
+func ReplaceInstance(
+	id string,
+	replacement string,
+	retired string,
+	svc rdsiface.RDSAPI,
+	interval time.Duration,
+) (replaced bool, err error) {
+	if err = checkIdentifiers(id, retired); err != nil {
+		return
+	}
+	status, err := instanceStatus(id, svc)
+	if err != nil {
+		return
+	}
+	replaced = status != ""
+	renames := [][2]string{{replacement, id}}
+	if replaced {
+		renames = [][2]string{{id, retired}, {replacement, id}}
+	}
+	err = renameInstances(renames, svc, interval)
+	return
+}
+
+// SwapInstances swaps the identifiers, and so the endpoints, of the
+// instances a and b, renaming a to temporary, b to a and temporary to
+// b. If any rename fails, the ones done are undone.
+func SwapInstances(
+	a string,
+	b string,
+	temporary string,
+	svc rdsiface.RDSAPI,
+	interval time.Duration,
+) error {
+	if a == b {
+		return fmt.Errorf("Can't swap %s with itself", a)
+	}
+	if err := checkIdentifiers(temporary); err != nil {
+		return err
+	}
+	for _, id := range []string{a, b} {
+		status, err := instanceStatus(id, svc)
+		if err != nil {
+			return err
+		}
+		if status != "available" {
+			return fmt.Errorf("%s instance is not available", id)
+		}
+	}
+	status, err := instanceStatus(temporary, svc)
+	if err != nil {
+		return err
+	}
+	if status != "" {
+		return fmt.Errorf("%s instance already exists", temporary)
+	}
+	return renameInstances(
+		[][2]string{{a, temporary}, {b, a}, {temporary, b}},
+		svc,
+		interval,
+	)
+}
@@ -0,0 +1,386 @@
+package odin_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/rds"
+
+	"github.com/poka-yoke/spaceflight/internal/test/mocks"
+	"github.com/poka-yoke/spaceflight/pkg/odin"
+)
+
+// instanceIDs returns the identifiers of the instances in svc.
+func instanceIDs(svc *mocks.RDSClient) (ids []string) {
+	output, _ := svc.DescribeDBInstances(&rds.DescribeDBInstancesInput{})
+	for _, instance := range output.DBInstances {
+		ids = append(ids, *instance.DBInstanceIdentifier)
+	}
+	return
+}
+
+func TestReplaceInstance(t *testing.T) {
+	tt := []struct {
+		name      string
+		instances []string
+		replaced  bool
+		err       string
+		expected  []string
+	}{
+		{
+			name:      "Existing instance",
+			instances: []string{"staging", "staging-new"},
+			replaced:  true,
+			expected:  []string{"staging-old", "staging"},
+		},
+		{
+			name:      "No instance to replace",
+			instances: []string{"staging-new"},
+			expected:  []string{"staging"},
+		},
+		{
+			name:      "Missing replacement",
+			instances: []string{"staging"},
+			replaced:  true,
+			err:       "DBInstanceNotFound: No such instance staging-new",
+			expected:  []string{"staging"},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(
+			tc.name,
+			func(t *testing.T) {
+				svc := mocks.NewRDSClient()
+				instances := []*rds.DBInstance{}
+				for _, id := range tc.instances {
+					instance := *exampleInstance1
+					instance.DBInstanceIdentifier = aws.String(id)
+					instances = append(instances, &instance)
+				}
+				svc.AddInstances(instances)
+				replaced, err := odin.ReplaceInstance(
+					"staging",
+					"staging-new",
+					"staging-old",
+					svc,
+					0,
+				)
+				switch {
+				case tc.err != "" && (err == nil || err.Error() != tc.err):
+					t.Errorf("Expected: %s, but got %v", tc.err, err)
+				case tc.err == "" && err != nil:
+					t.Errorf("Unexpected error: %s", err)
+				case replaced != tc.replaced:
+					t.Errorf("Expected replaced %v, got %v", tc.replaced, replaced)
+				}
+				ids := instanceIDs(svc)
+				if len(ids) != len(tc.expected) {
+					t.Fatalf("Expected instances %v, got %v", tc.expected, ids)
+				}
+				for i := range ids {
+					if ids[i] != tc.expected[i] {
+						t.Errorf("Expected instances %v, got %v", tc.expected, ids)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestWaitForStatusDeleted(t *testing.T) {
+	production := *exampleInstance1
+	svc := mocks.NewRDSClient()
+	svc.AddInstances([]*rds.DBInstance{&production})
+	_, err := svc.DeleteDBInstance(
+		&rds.DeleteDBInstanceInput{
+			DBInstanceIdentifier: aws.String("production-rds"),
+			SkipFinalSnapshot:    aws.Bool(true),
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := odin.WaitForStatus("production-rds", "", svc, 0); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if ids := instanceIDs(svc); len(ids) != 0 {
+		t.Errorf("Expected no instances, got %v", ids)
+	}
+}
+
+// throttledRDSClient throttles describing instances the given amount
+// of times.
+type throttledRDSClient struct {
+	*mocks.RDSClient
+	throttles int
+}
+
+func (m *throttledRDSClient) DescribeDBInstances(
+	params *rds.DescribeDBInstancesInput,
+) (*rds.DescribeDBInstancesOutput, error) {
+	if m.throttles > 0 {
+		m.throttles--
+		return nil, awserr.New("Throttling", "Rate exceeded", nil)
+	}
+	return m.RDSClient.DescribeDBInstances(params)
+}
+
+func TestWaitForStatusThrottled(t *testing.T) {
+	production := *exampleInstance1
+	svc := mocks.NewRDSClient()
+	svc.AddInstances([]*rds.DBInstance{&production})
+	err := odin.WaitForStatus(
+		"production-rds",
+		"available",
+		&throttledRDSClient{svc, 2},
+		0,
+	)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
+
+func TestWaitForStatusTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { odin.WaitTimeout = timeout }(odin.WaitTimeout)
+	odin.WaitTimeout = time.Millisecond
+	production := *exampleInstance1
+	svc := mocks.NewRDSClient()
+	svc.AddInstances([]*rds.DBInstance{&production})
+	err := odin.WaitForStatus("production-rds", "stopped", svc, time.Millisecond)
+	expected := "Timed out waiting for production-rds instance"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected: %s, but got %v", expected, err)
+	}
+}
+
+// modifyingRDSClient describes the instances in turn, as an instance
+// going through a modification.
+type modifyingRDSClient struct {
+	*mocks.RDSClient
+	instances []*rds.DBInstance
+}
+
+func (m *modifyingRDSClient) DescribeDBInstances(
+	params *rds.DescribeDBInstancesInput,
+) (*rds.DescribeDBInstancesOutput, error) {
+	instance := m.instances[0]
+	if len(m.instances) > 1 {
+		m.instances = m.instances[1:]
+	}
+	return &rds.DescribeDBInstancesOutput{
+		DBInstances: []*rds.DBInstance{instance},
+	}, nil
+}
+
+func TestWaitForModification(t *testing.T) {
+	adding := *exampleInstance1
+	adding.VpcSecurityGroups = []*rds.VpcSecurityGroupMembership{
+		{VpcSecurityGroupId: aws.String("sg-1"), Status: aws.String("adding")},
+	}
+	resizing := *exampleInstance1
+	resizing.PendingModifiedValues = &rds.PendingModifiedValues{
+		DBInstanceClass: aws.String("db.m4.large"),
+	}
+	modifying := *exampleInstance1
+	modifying.DBInstanceStatus = aws.String("modifying")
+	modified := *exampleInstance1
+	modified.VpcSecurityGroups = []*rds.VpcSecurityGroupMembership{
+		{VpcSecurityGroupId: aws.String("sg-1"), Status: aws.String("active")},
+	}
+	svc := &modifyingRDSClient{
+		instances: []*rds.DBInstance{
+			&adding,
+			&resizing,
+			&modifying,
+			&modifying,
+			&modified,
+		},
+	}
+	if err := odin.WaitForModification("production-rds", svc, 0); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(svc.instances) != 1 {
+		t.Errorf("Expected to wait until modified, %d left", len(svc.instances))
+	}
+}
+
+// failingRenameClient fails renaming instances to a given identifier
+// the given amount of times, after renaming them if applied.
+type failingRenameClient struct {
+	*mocks.RDSClient
+	failTo   string
+	failures int
+	applied  bool
+}
+
+func (m *failingRenameClient) ModifyDBInstance(
+	params *rds.ModifyDBInstanceInput,
+) (*rds.ModifyDBInstanceOutput, error) {
+	if aws.StringValue(params.NewDBInstanceIdentifier) == m.failTo &&
+		m.failures > 0 {
+		m.failures--
+		if m.applied {
+			m.RDSClient.ModifyDBInstance(params)
+		}
+		return nil, fmt.Errorf("Renaming to %s failed", m.failTo)
+	}
+	return m.RDSClient.ModifyDBInstance(params)
+}
+
+func TestSuffixedIdentifier(t *testing.T) {
+	tt := []struct {
+		id       string
+		expected string
+	}{
+		{"staging", "staging-old-20180102150405"},
+		{
+			strings.Repeat("a", 60),
+			strings.Repeat("a", 44) + "-old-20180102150405",
+		},
+		{
+			strings.Repeat("a", 43) + "-b",
+			strings.Repeat("a", 43) + "-old-20180102150405",
+		},
+	}
+	for _, tc := range tt {
+		id := odin.SuffixedIdentifier(tc.id, "old-20180102150405")
+		if id != tc.expected {
+			t.Errorf("Expected %s, got %s", tc.expected, id)
+		}
+		if len(id) > odin.MaxIdentifierLength {
+			t.Errorf("%s is too long", id)
+		}
+	}
+}
+
+func TestSwapInstances(t *testing.T) {
+	tt := []struct {
+		name      string
+		instances []string
+		stopped   string
+		a         string
+		temporary string
+		failTo    string
+		failures  int
+		applied   bool
+		err       string
+		expected  []string
+	}{
+		{
+			name:      "Swap",
+			instances: []string{"blue", "green"},
+			a:         "blue",
+			expected:  []string{"green", "blue"},
+		},
+		{
+			name:      "Rollback after the last rename",
+			instances: []string{"blue", "green"},
+			a:         "blue",
+			failTo:    "green",
+			failures:  1,
+			err:       "Renaming to green failed",
+			expected:  []string{"blue", "green"},
+		},
+		{
+			name:      "Rollback after the second rename",
+			instances: []string{"blue", "green"},
+			a:         "blue",
+			failTo:    "blue",
+			failures:  1,
+			err:       "Renaming to blue failed",
+			expected:  []string{"blue", "green"},
+		},
+		{
+			name:      "Rollback of the rename in progress",
+			instances: []string{"blue", "green"},
+			a:         "blue",
+			failTo:    "blue",
+			failures:  1,
+			applied:   true,
+			err:       "Renaming to blue failed",
+			expected:  []string{"blue", "green"},
+		},
+		{
+			name:      "Failed rollback",
+			instances: []string{"blue", "green"},
+			a:         "blue",
+			failTo:    "blue",
+			failures:  2,
+			err:       "Renaming to blue failed, and renaming blue-swap back to blue failed too: Renaming to blue failed",
+			expected:  []string{"blue-swap", "green"},
+		},
+		{
+			name:      "Same instance",
+			instances: []string{"blue"},
+			a:         "green",
+			err:       "Can't swap green with itself",
+			expected:  []string{"blue"},
+		},
+		{
+			name:      "Unavailable instance",
+			instances: []string{"blue", "green"},
+			stopped:   "green",
+			a:         "blue",
+			err:       "green instance is not available",
+			expected:  []string{"blue", "green"},
+		},
+		{
+			name:      "Too long temporary identifier",
+			instances: []string{"blue", "green"},
+			a:         "blue",
+			temporary: "blue-swap-" + strings.Repeat("x", 54),
+			err:       "blue-swap-" + strings.Repeat("x", 54) + " is longer than 63 characters",
+			expected:  []string{"blue", "green"},
+		},
+		{
+			name:      "Existing temporary instance",
+			instances: []string{"blue", "green", "blue-swap"},
+			a:         "blue",
+			err:       "blue-swap instance already exists",
+			expected:  []string{"blue", "green", "blue-swap"},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(
+			tc.name,
+			func(t *testing.T) {
+				svc := mocks.NewRDSClient()
+				instances := []*rds.DBInstance{}
+				for _, id := range tc.instances {
+					instance := *exampleInstance1
+					if id == tc.stopped {
+						instance = *exampleInstance2
+					}
+					instance.DBInstanceIdentifier = aws.String(id)
+					instances = append(instances, &instance)
+				}
+				svc.AddInstances(instances)
+				temporary := tc.temporary
+				if temporary == "" {
+					temporary = "blue-swap"
+				}
+				err := odin.SwapInstances(
+					tc.a,
+					"green",
+					temporary,
+					&failingRenameClient{svc, tc.failTo, tc.failures, tc.applied},
+					0,
+				)
+				switch {
+				case tc.err != "" && (err == nil || err.Error() != tc.err):
+					t.Errorf("Expected: %s, but got %v", tc.err, err)
+				case tc.err == "" && err != nil:
+					t.Errorf("Unexpected error: %s", err)
+				}
+				ids := instanceIDs(svc)
+				if strings.Join(ids, " ") != strings.Join(tc.expected, " ") {
+					t.Errorf("Expected instances %v, got %v", tc.expected, ids)
+				}
+			},
+		)
+	}
+}
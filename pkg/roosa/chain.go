@@ -0,0 +1,144 @@
+package roosa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+// DefaultMaxChainDepth is the number of CNAMEs and aliases a name can
+// go through before being considered excessive.
+const DefaultMaxChainDepth = 4
+
+// ChainError reports a loop of CNAMEs and aliases, or a chain of them
+// longer than allowed. Chain lists the names in order; for loops, the
+// first name is repeated at the end.
+type ChainError struct {
+	Chain []string `json:"chain"`
+	Loop  bool     `json:"loop"`
+	Max   int      `json:"max,omitempty"`
+}
+
+func (e *ChainError) Error() string {
+	chain := strings.Join(e.Chain, " -> ")
+	if e.Loop {
+		return fmt.Sprintf("CNAME loop: %s", chain)
+	}
+	return fmt.Sprintf(
+		"CNAME chain of %d references exceeds %d: %s",
+		len(e.Chain)-1,
+		e.Max,
+		chain,
+	)
+}
+
+// references returns the names each record name points to through
+// CNAMEs and aliases.
+func (rtl *ReferenceTreeList) references() map[string][]string {
+	out := make(map[string][]string)
+	for _, rrs := range rtl.records {
+		if target, ok := reference(rrs); ok {
+			name := normalizeName(aws.StringValue(rrs.Name))
+			if !hasString(out[name], target) {
+				out[name] = append(out[name], target)
+			}
+		}
+	}
+	return out
+}
+
+// CheckChains returns an error for each loop of CNAMEs and aliases,
+// and for each chain of them longer than maxDepth references. Chains
+// are only reported from the name starting them, not from every name
+// along.
+func (rtl *ReferenceTreeList) CheckChains(maxDepth int) (out []*ChainError) {
+	refs := rtl.references()
+	referenced := make(map[string]bool)
+	var names []string
+	for name, targets := range refs {
+		names = append(names, name)
+		for _, target := range targets {
+			referenced[target] = true
+		}
+	}
+	sort.Strings(names)
+
+	loops := make(map[string]bool)
+	longest := make(map[string][]string)
+	var walk func(path []string)
+	walk = func(path []string) {
+		name := path[len(path)-1]
+		for _, target := range refs[name] {
+			if i := indexOf(path, target); i >= 0 {
+				loop := append(append([]string{}, path[i:]...), target)
+				if key := loopKey(loop); !loops[key] {
+					loops[key] = true
+					out = append(out, &ChainError{Chain: loop, Loop: true})
+				}
+				continue
+			}
+			walk(append(path, target))
+		}
+		start := path[0]
+		if len(path) > len(longest[start]) {
+			longest[start] = append([]string{}, path...)
+		}
+	}
+	for _, name := range names {
+		walk([]string{name})
+	}
+	for _, name := range names {
+		if referenced[name] || len(longest[name])-1 <= maxDepth {
+			continue
+		}
+		out = append(out, &ChainError{Chain: longest[name], Max: maxDepth})
+	}
+	return
+}
+
+func indexOf(list []string, s string) int {
+	for i, elem := range list {
+		if elem == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// loopKey identifies a loop regardless of the name it was found from,
+// by rotating it to start at its smallest name.
+func loopKey(loop []string) string {
+	names := loop[:len(loop)-1]
+	first := 0
+	for i, name := range names {
+		if name < names[first] {
+			first = i
+		}
+	}
+	return strings.Join(append(append([]string{}, names[first:]...), names[:first]...), " ")
+}
+
+// LoadRecords reads records from a JSON document, either the output of
+// "aws route53 list-resource-record-sets" or a list of record sets, so
+// zone files kept in version control can be checked.
+func LoadRecords(r io.Reader) ([]*route53.ResourceRecordSet, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var records []*route53.ResourceRecordSet
+	if err := json.Unmarshal(data, &records); err == nil {
+		return records, nil
+	}
+	var list route53.ListResourceRecordSetsOutput
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	return list.ResourceRecordSets, nil
+}
@@ -0,0 +1,113 @@
+package roosa
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+var chainRecords = []*route53.ResourceRecordSet{
+	newRRS("a.example.com.", "CNAME", "b.example.com."),
+	newRRS("b.example.com.", "CNAME", "c.example.com."),
+	newRRS("c.example.com.", "CNAME", "a.example.com."),
+	newRRS("x.example.com.", "CNAME", "a.example.com."),
+	newRRS("self.example.com.", "CNAME", "self.example.com."),
+	newAlias("alias.example.com.", "A", "loop.example.com."),
+	newRRS("loop.example.com.", "CNAME", "alias.example.com."),
+	newRRS("l1.example.com.", "CNAME", "l2.example.com."),
+	newRRS("l2.example.com.", "CNAME", "l3.example.com."),
+	newRRS("l3.example.com.", "CNAME", "l4.example.com."),
+	newRRS("l4.example.com.", "CNAME", "origin.example.com."),
+	newRRS("origin.example.com.", "A", "10.0.0.1"),
+}
+
+func TestCheckChains(t *testing.T) {
+	data := []struct {
+		max      int
+		expected []string
+	}{
+		{
+			max: 4,
+			expected: []string{
+				"CNAME loop: a.example.com -> b.example.com -> c.example.com -> a.example.com",
+				"CNAME loop: alias.example.com -> loop.example.com -> alias.example.com",
+				"CNAME loop: self.example.com -> self.example.com",
+			},
+		},
+		{
+			max: 3,
+			expected: []string{
+				"CNAME loop: a.example.com -> b.example.com -> c.example.com -> a.example.com",
+				"CNAME loop: alias.example.com -> loop.example.com -> alias.example.com",
+				"CNAME loop: self.example.com -> self.example.com",
+				"CNAME chain of 4 references exceeds 3: l1.example.com -> l2.example.com -> l3.example.com -> l4.example.com -> origin.example.com",
+			},
+		},
+	}
+	for _, tc := range data {
+		var out []string
+		for _, err := range NewReferenceTreeList(chainRecords).CheckChains(tc.max) {
+			out = append(out, err.Error())
+		}
+		if strings.Join(out, "\n") != strings.Join(tc.expected, "\n") {
+			t.Errorf(
+				"Unexpected errors with max %d:\n%s\nExpected:\n%s",
+				tc.max,
+				strings.Join(out, "\n"),
+				strings.Join(tc.expected, "\n"),
+			)
+		}
+	}
+}
+
+func TestReferenceTreeListLoop(t *testing.T) {
+	output := NewReferenceTreeList(chainRecords).String()
+	for _, name := range []string{
+		"a.example.com.",
+		"b.example.com.",
+		"c.example.com.",
+		"x.example.com.",
+		"self.example.com.",
+		"alias.example.com.",
+		"loop.example.com.",
+	} {
+		if strings.Count(output, name+" ") != 1 {
+			t.Errorf("%s should appear once in:\n%s", name, output)
+		}
+	}
+}
+
+func TestLoadRecords(t *testing.T) {
+	data := []string{
+		`{"ResourceRecordSets": [
+			{"Name": "www.example.com.", "Type": "CNAME", "TTL": 300,
+			 "ResourceRecords": [{"Value": "web.example.com"}]},
+			{"Name": "example.com.", "Type": "A",
+			 "AliasTarget": {"DNSName": "web.example.com.", "HostedZoneId": "Z1",
+			                 "EvaluateTargetHealth": false}}
+		]}`,
+		`[
+			{"Name": "www.example.com.", "Type": "CNAME", "TTL": 300,
+			 "ResourceRecords": [{"Value": "web.example.com"}]},
+			{"Name": "example.com.", "Type": "A",
+			 "AliasTarget": {"DNSName": "web.example.com.", "HostedZoneId": "Z1",
+			                 "EvaluateTargetHealth": false}}
+		]`,
+	}
+	for _, document := range data {
+		records, err := LoadRecords(strings.NewReader(document))
+		if err != nil {
+			t.Errorf("Unexpected error %s loading:\n%s", err, document)
+			continue
+		}
+		if len(records) != 2 ||
+			recordValues(records[0])[0] != "web.example.com" ||
+			recordValues(records[1])[0] != "ALIAS web.example.com." {
+			t.Errorf("Unexpected records %v", records)
+		}
+	}
+	if _, err := LoadRecords(strings.NewReader("not json")); err == nil {
+		t.Error("Expected error loading invalid document")
+	}
+}
@@ -0,0 +1,262 @@
+package roosa
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+// Kinds of subdomain takeover risks.
+const (
+	RiskUnresolvable       = "unresolvable"
+	RiskUnclaimedEndpoint  = "unclaimed-endpoint"
+	RiskUnallocatedAddress = "unallocated-address"
+)
+
+// Finding is a record pointing to something which doesn't exist, and
+// could be claimed by someone else to serve content under its name.
+type Finding struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Target  string `json:"target"`
+	Risk    string `json:"risk"`
+	Message string `json:"message"`
+}
+
+// String method for Finding gets a String to be printed.
+func (f Finding) String() string {
+	return fmt.Sprintf(
+		"[%s] %s %s %s: %s",
+		f.Risk,
+		f.Name,
+		f.Type,
+		f.Target,
+		f.Message,
+	)
+}
+
+// fingerprint identifies an endpoint of a service which serves a known
+// error page for resources which don't exist, and lets anyone create
+// them.
+type fingerprint struct {
+	pattern string
+	service string
+	body    string
+}
+
+var s3Website = fingerprint{".s3-website", "S3 website", "NoSuchBucket"}
+
+// s3WebsiteEndpoint matches the regional S3 website endpoints, which
+// records are aliased to for serving the bucket named as the record.
+var s3WebsiteEndpoint = regexp.MustCompile(`^s3-website[.-][a-z0-9-]+\.amazonaws\.com$`)
+
+var takeoverFingerprints = []fingerprint{
+	s3Website,
+	{".s3.amazonaws.com", "S3", "NoSuchBucket"},
+	{".herokuapp.com", "Heroku", "No such app"},
+	{".herokudns.com", "Heroku", "No such app"},
+	{".github.io", "GitHub Pages", "There isn't a GitHub Pages site here"},
+	{".azurewebsites.net", "Azure", "404 Web Site not found"},
+	{".ghost.io", "Ghost", "The thing you were looking for is no longer here"},
+}
+
+// DanglingChecker finds records in Zones pointing to names which don't
+// resolve, to cloud endpoints which don't exist, or to addresses not
+// allocated in the account.
+type DanglingChecker struct {
+	Zones []string
+	// LookupHost resolves names, net.LookupHost by default.
+	LookupHost func(host string) ([]string, error)
+	// Fetch returns the body served for a URL.
+	Fetch func(url string) (string, error)
+	// Addresses allocated in the account. A records aren't checked
+	// when nil.
+	Addresses map[string]bool
+}
+
+// NewDanglingChecker returns a DanglingChecker for zones resolving names
+// and fetching pages from the Internet.
+func NewDanglingChecker(zones ...string) *DanglingChecker {
+	var names []string
+	for _, zone := range zones {
+		names = append(names, normalizeName(zone))
+	}
+	return &DanglingChecker{
+		Zones:      names,
+		LookupHost: net.LookupHost,
+		Fetch:      fetch,
+	}
+}
+
+// fetch returns the beginning of the body served by url.
+func fetch(url string) (string, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	return string(body), err
+}
+
+// inZone returns true if name belongs to any of the checker zones.
+func (c *DanglingChecker) inZone(name string) bool {
+	for _, zone := range c.Zones {
+		if name == zone || strings.HasSuffix(name, "."+zone) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns the takeover risks found in records.
+func (c *DanglingChecker) Check(records []*route53.ResourceRecordSet) (out []Finding) {
+	names := make(map[string]bool)
+	for _, rrs := range records {
+		names[normalizeName(aws.StringValue(rrs.Name))] = true
+	}
+	for _, rrs := range records {
+		finding := func(target, risk, format string, v ...interface{}) {
+			out = append(out, Finding{
+				Name:    normalizeName(aws.StringValue(rrs.Name)),
+				Type:    aws.StringValue(rrs.Type),
+				Target:  target,
+				Risk:    risk,
+				Message: fmt.Sprintf(format, v...),
+			})
+		}
+		for _, dep := range Dependencies(rrs) {
+			switch dep.Kind {
+			case DependencyCNAME, DependencyAlias:
+				if c.inZone(dep.Target) {
+					if !names[dep.Target] {
+						finding(dep.Target, RiskUnresolvable, "points to a missing record in the zones loaded")
+					}
+					continue
+				}
+				risk, message := c.checkExternal(
+					normalizeName(aws.StringValue(rrs.Name)),
+					dep.Target,
+				)
+				if risk != "" {
+					finding(dep.Target, risk, "%s", message)
+				}
+			case DependencyAddress:
+				if c.Addresses != nil && !c.Addresses[dep.Target] {
+					finding(dep.Target, RiskUnallocatedAddress, "address not allocated in the account")
+				}
+			}
+		}
+	}
+	return
+}
+
+// checkExternal returns the risk, and its explanation, for the target
+// of the record with the name, out of the zone, if any. The services
+// pick the resource to serve by the Host header, so pages are fetched
+// by the record name rather than by the target.
+func (c *DanglingChecker) checkExternal(name, target string) (risk, message string) {
+	var service *fingerprint
+	for i, fp := range takeoverFingerprints {
+		if strings.Contains(target, fp.pattern) {
+			service = &takeoverFingerprints[i]
+			break
+		}
+	}
+	if s3WebsiteEndpoint.MatchString(target) {
+		service = &s3Website
+	}
+	if _, err := c.LookupHost(target); err != nil {
+		dnsErr, ok := err.(*net.DNSError)
+		if !ok || dnsErr.Temporary() {
+			log.Printf("Couldn't resolve %s: %s\n", target, err)
+			return
+		}
+		risk = RiskUnresolvable
+		message = "name doesn't resolve"
+		if service != nil {
+			message = fmt.Sprintf("%s endpoint doesn't resolve", service.service)
+		}
+		return
+	}
+	if service == nil {
+		return
+	}
+	url := "http://" + name
+	body, err := c.Fetch(url)
+	if err != nil {
+		log.Printf("Couldn't fetch %s: %s\n", url, err)
+		return
+	}
+	if strings.Contains(body, service.body) {
+		risk = RiskUnclaimedEndpoint
+		message = fmt.Sprintf("%s reports the resource doesn't exist", service.service)
+	}
+	return
+}
+
+// Regions returns the names of the regions available to the account.
+func Regions(svc ec2iface.EC2API) (out []string, err error) {
+	res, err := svc.DescribeRegions(&ec2.DescribeRegionsInput{})
+	if err != nil {
+		return nil, err
+	}
+	for _, region := range res.Regions {
+		out = append(out, aws.StringValue(region.RegionName))
+	}
+	return
+}
+
+// AccountAddresses returns the Elastic IPs and the private and public
+// addresses of the network interfaces in the account, in the regions
+// of the clients.
+func AccountAddresses(clients ...ec2iface.EC2API) (map[string]bool, error) {
+	out := make(map[string]bool)
+	for _, svc := range clients {
+		if err := regionAddresses(svc, out); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// regionAddresses adds the addresses in the region of svc to out. The
+// network interfaces aren't paginated by this API version, which
+// returns them all.
+func regionAddresses(svc ec2iface.EC2API, out map[string]bool) error {
+	addresses, err := svc.DescribeAddresses(&ec2.DescribeAddressesInput{})
+	if err != nil {
+		return err
+	}
+	for _, address := range addresses.Addresses {
+		out[aws.StringValue(address.PublicIp)] = true
+	}
+	enis, err := svc.DescribeNetworkInterfaces(&ec2.DescribeNetworkInterfacesInput{})
+	if err != nil {
+		return err
+	}
+	for _, eni := range enis.NetworkInterfaces {
+		for _, address := range eni.PrivateIpAddresses {
+			out[aws.StringValue(address.PrivateIpAddress)] = true
+			if address.Association != nil {
+				out[aws.StringValue(address.Association.PublicIp)] = true
+			}
+		}
+		for _, address := range eni.Ipv6Addresses {
+			out[aws.StringValue(address.Ipv6Address)] = true
+		}
+	}
+	return nil
+}
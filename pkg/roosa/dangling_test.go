@@ -0,0 +1,158 @@
+package roosa
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+func TestDanglingCheckerCheck(t *testing.T) {
+	c := &DanglingChecker{
+		Zones: []string{"example.com"},
+		LookupHost: func(host string) ([]string, error) {
+			switch host {
+			case "gone.example.net", "old-lb.us-east-1.elb.amazonaws.com":
+				return nil, &net.DNSError{Err: "no such host", Name: host}
+			case "flaky.example.net":
+				return nil, &net.DNSError{Err: "timeout", Name: host, IsTemporary: true}
+			}
+			return []string{"192.0.2.1"}, nil
+		},
+		Fetch: func(url string) (string, error) {
+			switch url {
+			case "http://assets.example.com",
+				"http://static.example.com":
+				return "<Code>NoSuchBucket</Code>", nil
+			case "http://broken.example.com":
+				return "", errors.New("connection refused")
+			case "http://live.herokudns.com",
+				"http://unclaimed.example.com":
+				return "No such app", nil
+			}
+			return "Welcome", nil
+		},
+		Addresses: map[string]bool{"10.0.0.1": true},
+	}
+	records := []*route53.ResourceRecordSet{
+		newRRS("www.example.com.", "CNAME", "web.example.com"),
+		newRRS("web.example.com.", "A", "10.0.0.1"),
+		newRRS("old.example.com.", "A", "10.0.0.9"),
+		newRRS("missing.example.com.", "CNAME", "nothing.example.com"),
+		newRRS("blog.example.com.", "CNAME", "gone.example.net"),
+		newRRS("flaky.example.com.", "CNAME", "flaky.example.net"),
+		newRRS("assets.example.com.", "CNAME", "assets.s3-website-us-east-1.amazonaws.com"),
+		newRRS("app.example.com.", "CNAME", "app.herokuapp.com"),
+		newRRS("broken.example.com.", "CNAME", "broken.herokuapp.com"),
+		newRRS("live.example.com.", "CNAME", "live.herokudns.com"),
+		newRRS("unclaimed.example.com.", "CNAME", "unclaimed.herokudns.com"),
+		newAlias("lb.example.com.", "A", "old-lb.us-east-1.elb.amazonaws.com."),
+		newAlias("static.example.com.", "A", "s3-website.eu-west-2.amazonaws.com."),
+		newAlias("site.example.com.", "A", "s3-website-us-east-1.amazonaws.com."),
+	}
+	expected := map[string]string{
+		"old.example.com":       RiskUnallocatedAddress,
+		"missing.example.com":   RiskUnresolvable,
+		"blog.example.com":      RiskUnresolvable,
+		"assets.example.com":    RiskUnclaimedEndpoint,
+		"lb.example.com":        RiskUnresolvable,
+		"static.example.com":    RiskUnclaimedEndpoint,
+		"unclaimed.example.com": RiskUnclaimedEndpoint,
+	}
+	findings := c.Check(records)
+	if len(findings) != len(expected) {
+		t.Errorf("Expected %d findings, found %v", len(expected), findings)
+	}
+	for _, f := range findings {
+		if expected[f.Name] != f.Risk {
+			t.Errorf("Unexpected finding %s", f)
+		}
+	}
+
+	c.Addresses = nil
+	if findings := c.Check(records[2:3]); len(findings) != 0 {
+		t.Errorf("Addresses shouldn't be checked, found %v", findings)
+	}
+}
+
+type mockEC2Client struct {
+	ec2iface.EC2API
+	region string
+}
+
+func (m *mockEC2Client) DescribeRegions(
+	params *ec2.DescribeRegionsInput,
+) (*ec2.DescribeRegionsOutput, error) {
+	return &ec2.DescribeRegionsOutput{
+		Regions: []*ec2.Region{
+			{RegionName: aws.String("us-east-1")},
+			{RegionName: aws.String("eu-west-1")},
+		},
+	}, nil
+}
+
+func (m *mockEC2Client) DescribeAddresses(
+	params *ec2.DescribeAddressesInput,
+) (*ec2.DescribeAddressesOutput, error) {
+	if m.region == "eu-west-1" {
+		return &ec2.DescribeAddressesOutput{
+			Addresses: []*ec2.Address{
+				{PublicIp: aws.String("203.0.113.1")},
+			},
+		}, nil
+	}
+	return &ec2.DescribeAddressesOutput{
+		Addresses: []*ec2.Address{
+			{PublicIp: aws.String("198.51.100.1")},
+		},
+	}, nil
+}
+
+func (m *mockEC2Client) DescribeNetworkInterfaces(
+	params *ec2.DescribeNetworkInterfacesInput,
+) (*ec2.DescribeNetworkInterfacesOutput, error) {
+	return &ec2.DescribeNetworkInterfacesOutput{
+		NetworkInterfaces: []*ec2.NetworkInterface{
+			{
+				PrivateIpAddresses: []*ec2.NetworkInterfacePrivateIpAddress{
+					{
+						PrivateIpAddress: aws.String("10.0.0.1"),
+						Association: &ec2.NetworkInterfaceAssociation{
+							PublicIp: aws.String("198.51.100.2"),
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func TestRegions(t *testing.T) {
+	regions, err := Regions(&mockEC2Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(regions, " ") != "us-east-1 eu-west-1" {
+		t.Errorf("Unexpected regions %v", regions)
+	}
+}
+
+func TestAccountAddresses(t *testing.T) {
+	out, err := AccountAddresses(
+		&mockEC2Client{region: "us-east-1"},
+		&mockEC2Client{region: "eu-west-1"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, address := range []string{"198.51.100.1", "198.51.100.2", "10.0.0.1", "203.0.113.1"} {
+		if !out[address] {
+			t.Errorf("%s not found in %v", address, out)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package roosa
+
+import (
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+// RecordFilter selects records by type and name. Empty fields match
+// every record. Name is a shell pattern, like "*.example.com", matched
+// against names without their trailing dot.
+type RecordFilter struct {
+	Types []string
+	Name  string
+}
+
+// Match returns true if rrs passes the filter.
+func (f RecordFilter) Match(rrs *route53.ResourceRecordSet) bool {
+	return f.MatchType(aws.StringValue(rrs.Type)) &&
+		f.MatchName(aws.StringValue(rrs.Name))
+}
+
+// MatchType returns true if records of type kind pass the filter.
+func (f RecordFilter) MatchType(kind string) bool {
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if strings.EqualFold(t, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchName returns true if records called name pass the filter.
+func (f RecordFilter) MatchName(name string) bool {
+	if f.Name == "" {
+		return true
+	}
+	matched, err := path.Match(normalizeName(f.Name), normalizeName(name))
+	return err == nil && matched
+}
+
+// Apply returns zones with only the records passing the filter.
+func (f RecordFilter) Apply(zones []Zone) (out []Zone) {
+	for _, zone := range zones {
+		filtered := zone
+		filtered.Records = nil
+		for _, rrs := range zone.Records {
+			if f.Match(rrs) {
+				filtered.Records = append(filtered.Records, rrs)
+			}
+		}
+		out = append(out, filtered)
+	}
+	return
+}
@@ -0,0 +1,35 @@
+package roosa
+
+import (
+	"testing"
+)
+
+func TestRecordFilterApply(t *testing.T) {
+	data := []struct {
+		filter   RecordFilter
+		expected []int
+	}{
+		{RecordFilter{}, []int{2, 2}},
+		{RecordFilter{Types: []string{"cname"}}, []int{1, 1}},
+		{RecordFilter{Name: "*.example.com."}, []int{2, 0}},
+		{RecordFilter{Types: []string{"A"}, Name: "*.example.org"}, []int{0, 0}},
+		{RecordFilter{Name: "[invalid"}, []int{0, 0}},
+	}
+	for _, tc := range data {
+		zones := tc.filter.Apply(crossZones)
+		for i, zone := range zones {
+			if len(zone.Records) != tc.expected[i] {
+				t.Errorf(
+					"Expected %d records in %s for %v, found %d",
+					tc.expected[i],
+					zone.Name,
+					tc.filter,
+					len(zone.Records),
+				)
+			}
+		}
+	}
+	if len(crossZones[0].Records) != 2 {
+		t.Error("Filter modified the original zones")
+	}
+}
@@ -0,0 +1,354 @@
+package roosa
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/awalterschulze/gographviz"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+
+	"github.com/poka-yoke/spaceflight/internal/diagram"
+)
+
+// Kinds of dependencies between a record and the names or addresses it
+// points to.
+const (
+	DependencyCNAME   = "cname"
+	DependencyAlias   = "alias"
+	DependencyAddress = "address"
+	DependencyMX      = "mx"
+	DependencySRV     = "srv"
+	DependencyNS      = "ns"
+)
+
+// Dependency is a name or address a record needs to resolve.
+type Dependency struct {
+	Target string
+	Kind   string
+}
+
+// normalizeName lowercases a DNS name and removes its trailing dot, so
+// names can be compared.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+// lastField returns the last space separated field of value, which is
+// the target name of MX and SRV records.
+func lastField(value string) string {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1]
+}
+
+// Dependencies returns the names and addresses rrs points to, one per
+// value: CNAME, MX, SRV and NS targets, alias targets and the addresses
+// of A and AAAA records.
+func Dependencies(rrs *route53.ResourceRecordSet) (out []Dependency) {
+	if rrs.AliasTarget != nil {
+		return []Dependency{{
+			Target: normalizeName(aws.StringValue(rrs.AliasTarget.DNSName)),
+			Kind:   DependencyAlias,
+		}}
+	}
+	for _, record := range rrs.ResourceRecords {
+		value := aws.StringValue(record.Value)
+		switch aws.StringValue(rrs.Type) {
+		case "CNAME":
+			out = append(out, Dependency{normalizeName(value), DependencyCNAME})
+		case "A", "AAAA":
+			out = append(out, Dependency{value, DependencyAddress})
+		case "MX":
+			out = append(out, Dependency{normalizeName(lastField(value)), DependencyMX})
+		case "SRV":
+			out = append(out, Dependency{normalizeName(lastField(value)), DependencySRV})
+		case "NS":
+			out = append(out, Dependency{normalizeName(value), DependencyNS})
+		}
+	}
+	return
+}
+
+// recordValues returns the values of rrs as text. Alias records show
+// their target.
+func recordValues(rrs *route53.ResourceRecordSet) (out []string) {
+	if rrs.AliasTarget != nil {
+		return []string{
+			fmt.Sprintf("ALIAS %s", aws.StringValue(rrs.AliasTarget.DNSName)),
+		}
+	}
+	for _, record := range rrs.ResourceRecords {
+		out = append(out, aws.StringValue(record.Value))
+	}
+	return
+}
+
+// Kinds of nodes in a Graph.
+const (
+	NodeRecord   = "record"
+	NodeExternal = "external"
+	NodeAddress  = "address"
+)
+
+// GraphNode is a name, with the types of its records, or an address in
+// a Graph. Names without records in the zones loaded are external. Zone
+// is only set when the graph is built from several zones, and Resources
+// when the AWS resources behind the nodes are set.
+type GraphNode struct {
+	ID        string     `json:"id"`
+	Kind      string     `json:"kind"`
+	Zone      string     `json:"zone,omitempty"`
+	Types     []string   `json:"types,omitempty"`
+	Resources []Resource `json:"resources,omitempty"`
+}
+
+// GraphEdge links a name to a name or address its records point to.
+// CrossZone edges link records in different hosted zones.
+type GraphEdge struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Kind      string `json:"kind"`
+	CrossZone bool   `json:"cross_zone,omitempty"`
+}
+
+// Graph represents the dependencies among DNS records.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+var graphTypes = []string{
+	"A",
+	"AAAA",
+	"CNAME",
+	"MX",
+	"NS",
+	"SRV",
+}
+
+// NewGraph builds the Graph of dependencies among records: CNAME, MX,
+// SRV and NS targets, alias targets and addresses, for every value of
+// each record.
+func NewGraph(records []*route53.ResourceRecordSet) *Graph {
+	return newGraph(records, nil)
+}
+
+// NewZoneGraph builds the Graph of dependencies among the records of
+// several zones, resolving references across them.
+func NewZoneGraph(zones []Zone) *Graph {
+	records, index := indexZones(zones)
+	return newGraph(records, index)
+}
+
+// newGraph builds the Graph of records, placing each in its zone
+// according to zones, if any.
+func newGraph(records []*route53.ResourceRecordSet, zones zoneIndex) *Graph {
+	g := &Graph{}
+	records = FilterResourceRecords(
+		records,
+		graphTypes,
+		func(elem *route53.ResourceRecordSet, filter string) *route53.ResourceRecordSet {
+			if *elem.Type == filter {
+				return elem
+			}
+			return nil
+		},
+	)
+	index := make(map[string]int)
+	for _, rrs := range records {
+		name := normalizeName(aws.StringValue(rrs.Name))
+		i, ok := index[name]
+		if !ok {
+			i = len(g.Nodes)
+			index[name] = i
+			g.Nodes = append(g.Nodes, GraphNode{
+				ID:   name,
+				Kind: NodeRecord,
+				Zone: zones[rrs],
+			})
+		}
+		if !hasString(g.Nodes[i].Types, *rrs.Type) {
+			g.Nodes[i].Types = append(g.Nodes[i].Types, *rrs.Type)
+		}
+	}
+	seen := make(map[GraphEdge]bool)
+	for _, rrs := range records {
+		name := normalizeName(aws.StringValue(rrs.Name))
+		for _, dep := range Dependencies(rrs) {
+			if _, ok := index[dep.Target]; !ok {
+				kind := NodeExternal
+				if dep.Kind == DependencyAddress {
+					kind = NodeAddress
+				}
+				index[dep.Target] = len(g.Nodes)
+				g.Nodes = append(g.Nodes, GraphNode{ID: dep.Target, Kind: kind})
+			}
+			edge := GraphEdge{From: name, To: dep.Target, Kind: dep.Kind}
+			to := g.Nodes[index[dep.Target]]
+			if to.Kind == NodeRecord && to.Zone != g.Nodes[index[name]].Zone {
+				edge.CrossZone = true
+			}
+			if !seen[edge] {
+				seen[edge] = true
+				g.Edges = append(g.Edges, edge)
+			}
+		}
+	}
+	for i := range g.Nodes {
+		sort.Strings(g.Nodes[i].Types)
+	}
+	return g
+}
+
+// SetResources adds to each address and external name the AWS
+// resources reachable at it, and to records aliasing S3 website
+// endpoints the bucket named after them.
+func (g *Graph) SetResources(resources Resources) {
+	index := make(map[string]int)
+	for i, node := range g.Nodes {
+		index[node.ID] = i
+		if node.Kind != NodeRecord {
+			g.Nodes[i].Resources = resources.Target(node.ID)
+		}
+	}
+	for _, edge := range g.Edges {
+		if strings.HasPrefix(edge.To, "s3-website") {
+			node := &g.Nodes[index[edge.From]]
+			node.Resources = append(node.Resources, resources[bucketKey(edge.From)]...)
+		}
+	}
+}
+
+func hasString(list []string, s string) bool {
+	for _, elem := range list {
+		if elem == s {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeLabel returns the name of a node followed by its record types
+// and its resources, one per line.
+func nodeLabel(node GraphNode, separator string) string {
+	lines := []string{node.ID}
+	if len(node.Types) > 0 {
+		lines = append(lines, strings.Join(node.Types, ","))
+	}
+	for _, resource := range node.Resources {
+		lines = append(lines, resource.String())
+	}
+	return strings.Join(lines, separator)
+}
+
+// zones returns the sorted list of zones in the graph.
+func (g *Graph) zones() (out []string) {
+	for _, node := range g.Nodes {
+		if node.Zone != "" && !hasString(out, node.Zone) {
+			out = append(out, node.Zone)
+		}
+	}
+	sort.Strings(out)
+	return
+}
+
+// DOT returns the graph in DOT format, with a cluster per zone. Cross
+// zone edges are drawn bold.
+func (g *Graph) DOT() string {
+	graph := gographviz.NewEscape()
+	if err := graph.SetName("G"); err != nil {
+		log.Println(err)
+	}
+	if err := graph.SetDir(true); err != nil {
+		log.Println(err)
+	}
+	for _, zone := range g.zones() {
+		if err := graph.AddSubGraph(
+			"G",
+			"cluster_"+zone,
+			map[string]string{"label": zone},
+		); err != nil {
+			log.Println(err)
+		}
+	}
+	for _, node := range g.Nodes {
+		parent := "G"
+		if node.Zone != "" {
+			parent = "cluster_" + node.Zone
+		}
+		attrs := map[string]string{"label": nodeLabel(node, "\\n")}
+		switch node.Kind {
+		case NodeExternal:
+			attrs["shape"] = "box"
+			attrs["style"] = "dashed"
+		case NodeAddress:
+			attrs["shape"] = "ellipse"
+		default:
+			attrs["shape"] = "box"
+		}
+		if err := graph.AddNode(parent, node.ID, attrs); err != nil {
+			log.Println(err)
+		}
+	}
+	for _, edge := range g.Edges {
+		attrs := map[string]string{"label": edge.Kind}
+		if edge.CrossZone {
+			attrs["style"] = "bold"
+		}
+		if err := graph.AddEdge(edge.From, edge.To, true, attrs); err != nil {
+			log.Println(err)
+		}
+	}
+	return graph.String()
+}
+
+// mermaidShape returns the shape of node in Mermaid flowcharts.
+func mermaidShape(node GraphNode) string {
+	switch node.Kind {
+	case NodeExternal:
+		return diagram.Rounded
+	case NodeAddress:
+		return diagram.Circle
+	}
+	return diagram.Box
+}
+
+// Mermaid returns the graph as a Mermaid flowchart, with a subgraph per
+// zone. External names are drawn with rounded borders, addresses as
+// circles, and cross zone edges thick.
+func (g *Graph) Mermaid() string {
+	m := diagram.NewMermaid()
+	for _, zone := range g.zones() {
+		m.Subgraph(zone)
+		for _, node := range g.Nodes {
+			if node.Zone == zone {
+				m.Node(node.ID, nodeLabel(node, "<br/>"), mermaidShape(node))
+			}
+		}
+		m.End()
+	}
+	for _, node := range g.Nodes {
+		if node.Zone == "" {
+			m.Node(node.ID, nodeLabel(node, "<br/>"), mermaidShape(node))
+		}
+	}
+	for _, edge := range g.Edges {
+		arrow := diagram.Arrow
+		if edge.CrossZone {
+			arrow = diagram.Thick
+		}
+		m.Edge(edge.From, edge.To, edge.Kind, arrow)
+	}
+	return m.String()
+}
+
+// JSON returns the graph as a JSON document with a list of nodes and a
+// list of edges.
+func (g *Graph) JSON() (string, error) {
+	return diagram.JSON(g)
+}
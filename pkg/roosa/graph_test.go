@@ -0,0 +1,198 @@
+package roosa
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+func newRRS(name, kind string, values ...string) *route53.ResourceRecordSet {
+	rrs := &route53.ResourceRecordSet{
+		Name: aws.String(name),
+		Type: aws.String(kind),
+	}
+	for _, value := range values {
+		rrs.ResourceRecords = append(
+			rrs.ResourceRecords,
+			&route53.ResourceRecord{Value: aws.String(value)},
+		)
+	}
+	return rrs
+}
+
+func newAlias(name, kind, target string) *route53.ResourceRecordSet {
+	return &route53.ResourceRecordSet{
+		Name: aws.String(name),
+		Type: aws.String(kind),
+		AliasTarget: &route53.AliasTarget{
+			DNSName:      aws.String(target),
+			HostedZoneId: aws.String("Z1234"),
+		},
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	data := []struct {
+		rrs      *route53.ResourceRecordSet
+		expected []Dependency
+	}{
+		{
+			rrs:      newRRS("www.example.com.", "CNAME", "Web.Example.com."),
+			expected: []Dependency{{"web.example.com", DependencyCNAME}},
+		},
+		{
+			rrs: newRRS("web.example.com.", "A", "10.0.0.1", "10.0.0.2"),
+			expected: []Dependency{
+				{"10.0.0.1", DependencyAddress},
+				{"10.0.0.2", DependencyAddress},
+			},
+		},
+		{
+			rrs:      newAlias("example.com.", "A", "lb-1.us-east-1.elb.amazonaws.com."),
+			expected: []Dependency{{"lb-1.us-east-1.elb.amazonaws.com", DependencyAlias}},
+		},
+		{
+			rrs: newRRS("example.com.", "MX", "10 mx1.example.com.", "20 mx2.example.net."),
+			expected: []Dependency{
+				{"mx1.example.com", DependencyMX},
+				{"mx2.example.net", DependencyMX},
+			},
+		},
+		{
+			rrs:      newRRS("_sip._tcp.example.com.", "SRV", "0 5 5060 sip.example.com."),
+			expected: []Dependency{{"sip.example.com", DependencySRV}},
+		},
+		{
+			rrs:      newRRS("dev.example.com.", "NS", "ns-1.awsdns-01.org."),
+			expected: []Dependency{{"ns-1.awsdns-01.org", DependencyNS}},
+		},
+		{
+			rrs: newRRS("example.com.", "TXT", "\"v=spf1 -all\""),
+		},
+	}
+	for _, tc := range data {
+		out := Dependencies(tc.rrs)
+		if len(out) != len(tc.expected) {
+			t.Errorf("Unexpected dependencies %v for %s", out, *tc.rrs.Name)
+			continue
+		}
+		for i := range out {
+			if out[i] != tc.expected[i] {
+				t.Errorf("%v is not %v", out[i], tc.expected[i])
+			}
+		}
+	}
+}
+
+var graphRecords = []*route53.ResourceRecordSet{
+	newRRS("example.com.", "A", "10.0.0.1"),
+	newRRS("example.com.", "MX", "10 mail.example.com."),
+	newRRS("mail.example.com.", "A", "10.0.0.2", "10.0.0.3"),
+	newRRS("www.example.com.", "CNAME", "example.com"),
+	newAlias("cdn.example.com.", "A", "d111111abcdef8.cloudfront.net."),
+	newRRS("dev.example.com.", "NS", "ns-1.awsdns-01.org."),
+	newRRS("example.com.", "TXT", "\"v=spf1 -all\""),
+}
+
+func TestNewGraph(t *testing.T) {
+	g := NewGraph(graphRecords)
+	nodes := make(map[string]GraphNode)
+	for _, node := range g.Nodes {
+		nodes[node.ID] = node
+	}
+	data := []struct {
+		id    string
+		kind  string
+		types string
+	}{
+		{"example.com", NodeRecord, "A,MX"},
+		{"mail.example.com", NodeRecord, "A"},
+		{"10.0.0.3", NodeAddress, ""},
+		{"d111111abcdef8.cloudfront.net", NodeExternal, ""},
+		{"ns-1.awsdns-01.org", NodeExternal, ""},
+	}
+	for _, tc := range data {
+		node, ok := nodes[tc.id]
+		if !ok {
+			t.Errorf("Node %s not found", tc.id)
+			continue
+		}
+		if node.Kind != tc.kind || strings.Join(node.Types, ",") != tc.types {
+			t.Errorf("Unexpected node %v", node)
+		}
+	}
+	if len(g.Nodes) != 10 {
+		t.Errorf("Expected 10 nodes, found %d: %v", len(g.Nodes), g.Nodes)
+	}
+	edges := []GraphEdge{
+		{"example.com", "10.0.0.1", DependencyAddress, false},
+		{"example.com", "mail.example.com", DependencyMX, false},
+		{"www.example.com", "example.com", DependencyCNAME, false},
+		{"cdn.example.com", "d111111abcdef8.cloudfront.net", DependencyAlias, false},
+		{"dev.example.com", "ns-1.awsdns-01.org", DependencyNS, false},
+	}
+	for _, edge := range edges {
+		found := false
+		for _, e := range g.Edges {
+			found = found || e == edge
+		}
+		if !found {
+			t.Errorf("Edge %v not found in %v", edge, g.Edges)
+		}
+	}
+	if len(g.Edges) != 7 {
+		t.Errorf("Expected 7 edges, found %d: %v", len(g.Edges), g.Edges)
+	}
+}
+
+func TestGraphFormats(t *testing.T) {
+	g := NewGraph(graphRecords)
+	dot := g.DOT()
+	for _, expected := range []string{"digraph", "\"www.example.com\"", "cname"} {
+		if !strings.Contains(dot, expected) {
+			t.Errorf("%q not found in DOT output:\n%s", expected, dot)
+		}
+	}
+	mermaid := g.Mermaid()
+	for _, expected := range []string{
+		"graph LR\n",
+		"  n_www_example_com -->|\"cname\"| n_example_com\n",
+		"  n_10_0_0_1((\"10.0.0.1\"))\n",
+		"  n_example_com[\"example.com<br/>A,MX\"]\n",
+	} {
+		if !strings.Contains(mermaid, expected) {
+			t.Errorf("%q not found in Mermaid output:\n%s", expected, mermaid)
+		}
+	}
+	out, err := g.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed Graph
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Nodes) != len(g.Nodes) || len(parsed.Edges) != len(g.Edges) {
+		t.Errorf("Unexpected JSON output %s", out)
+	}
+}
+
+func TestReferenceTreeListAlias(t *testing.T) {
+	rtl := NewReferenceTreeList([]*route53.ResourceRecordSet{
+		newRRS("web.example.com.", "A", "10.0.0.1"),
+		newAlias("example.com.", "A", "web.example.com."),
+		newAlias("cdn.example.com.", "A", "d111111abcdef8.cloudfront.net."),
+	})
+	output := rtl.String()
+	for _, expected := range []string{
+		"web.example.com. A 10.0.0.1\n\texample.com. A ALIAS web.example.com.\n",
+		"cdn.example.com. A ALIAS d111111abcdef8.cloudfront.net.\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("%q not found in:\n%s", expected, output)
+		}
+	}
+}
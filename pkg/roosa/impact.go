@@ -0,0 +1,69 @@
+package roosa
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Impact is a name depending on a record or address, directly or
+// through a chain of references. Path goes from Name to the target,
+// and Kind is the dependency of Name on the next name in Path.
+type Impact struct {
+	Name  string   `json:"name"`
+	Kind  string   `json:"kind"`
+	Depth int      `json:"depth"`
+	Path  []string `json:"path"`
+}
+
+// String method for Impact gets a String to be printed.
+func (i Impact) String() string {
+	return fmt.Sprintf("%s (%s): %s", i.Name, i.Kind, strings.Join(i.Path, " -> "))
+}
+
+// Impact returns every name which would be affected by changes to
+// target, a name or an address, walking the references in the graph
+// upward: CNAME chains, aliases, and MX, SRV and NS targets. Names only
+// affected through MX, SRV or NS records are followed through CNAMEs
+// and aliases only, as the names pointing to them with those records
+// need their addresses instead. Names are sorted by distance to target,
+// and reported once, with their shortest path.
+func (g *Graph) Impact(target string) ([]Impact, error) {
+	target = normalizeName(target)
+	found := false
+	for _, node := range g.Nodes {
+		found = found || node.ID == target
+	}
+	if !found {
+		return nil, fmt.Errorf("%s not found in the graph", target)
+	}
+	dependants := make(map[string][]GraphEdge)
+	for _, edge := range g.Edges {
+		dependants[edge.To] = append(dependants[edge.To], edge)
+	}
+	paths := map[string][]string{target: {target}}
+	indirect := make(map[string]bool)
+	var out []Impact
+	for queue := []string{target}; len(queue) > 0; queue = queue[1:] {
+		name := queue[0]
+		for _, edge := range dependants[name] {
+			if _, ok := paths[edge.From]; ok {
+				continue
+			}
+			aliasing := edge.Kind == DependencyCNAME || edge.Kind == DependencyAlias
+			if indirect[name] && !aliasing {
+				continue
+			}
+			indirect[edge.From] = indirect[name] || !aliasing && edge.Kind != DependencyAddress
+			path := append([]string{edge.From}, paths[name]...)
+			paths[edge.From] = path
+			out = append(out, Impact{
+				Name:  edge.From,
+				Kind:  edge.Kind,
+				Depth: len(path) - 1,
+				Path:  path,
+			})
+			queue = append(queue, edge.From)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,70 @@
+package roosa
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+var impactRecords = []*route53.ResourceRecordSet{
+	newRRS("origin.example.com.", "A", "10.0.0.1"),
+	newRRS("web.example.com.", "CNAME", "origin.example.com."),
+	newRRS("www.example.com.", "CNAME", "web.example.com."),
+	newAlias("example.com.", "A", "www.example.com."),
+	newRRS("mail.example.com.", "A", "10.0.0.2"),
+	newRRS("example.com.", "MX", "10 mail.example.com."),
+	newRRS("mx.example.com.", "CNAME", "example.com."),
+	newRRS("other.example.com.", "A", "10.0.0.3"),
+}
+
+func TestImpact(t *testing.T) {
+	g := NewGraph(impactRecords)
+	data := []struct {
+		target   string
+		expected []string
+	}{
+		{
+			target: "10.0.0.1",
+			expected: []string{
+				"origin.example.com (address): origin.example.com -> 10.0.0.1",
+				"web.example.com (cname): web.example.com -> origin.example.com -> 10.0.0.1",
+				"www.example.com (cname): www.example.com -> web.example.com -> origin.example.com -> 10.0.0.1",
+				"example.com (alias): example.com -> www.example.com -> web.example.com -> origin.example.com -> 10.0.0.1",
+				"mx.example.com (cname): mx.example.com -> example.com -> www.example.com -> web.example.com -> origin.example.com -> 10.0.0.1",
+			},
+		},
+		{
+			target: "Mail.Example.com.",
+			expected: []string{
+				"example.com (mx): example.com -> mail.example.com",
+				"mx.example.com (cname): mx.example.com -> example.com -> mail.example.com",
+			},
+		},
+		{
+			target: "other.example.com",
+		},
+	}
+	for _, tc := range data {
+		impacts, err := g.Impact(tc.target)
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %s", tc.target, err)
+			continue
+		}
+		var out []string
+		for _, impact := range impacts {
+			out = append(out, impact.String())
+		}
+		if strings.Join(out, "\n") != strings.Join(tc.expected, "\n") {
+			t.Errorf(
+				"Unexpected impact of %s:\n%s\nExpected:\n%s",
+				tc.target,
+				strings.Join(out, "\n"),
+				strings.Join(tc.expected, "\n"),
+			)
+		}
+	}
+	if _, err := g.Impact("missing.example.com"); err == nil {
+		t.Error("Expected error for unknown target")
+	}
+}
@@ -9,10 +9,17 @@ import (
 
 // Node type represents the reference between data.
 type Node struct {
-	parent   *Node
-	children []*Node
-	content  *route53.ResourceRecordSet
-	indent   int
+	parent    *Node
+	children  []*Node
+	content   *route53.ResourceRecordSet
+	zone      string
+	resources []Resource
+	indent    int
+}
+
+// IsCrossZone returns true if n references a record in another zone.
+func (n *Node) IsCrossZone() bool {
+	return n.parent != nil && n.parent.zone != n.zone
 }
 
 // IsRoot returns true if n is a root node.
@@ -20,18 +27,33 @@ func (n *Node) IsRoot() bool {
 	return n.parent == nil
 }
 
+// reaches returns true if other is n or any of its descendants, so
+// making n a child of other would create a loop.
+func (n *Node) reaches(other *Node) bool {
+	if n == other {
+		return true
+	}
+	for _, child := range n.children {
+		if child.reaches(other) {
+			return true
+		}
+	}
+	return false
+}
+
 // String method allows printing of nodes and its children.
 func (n *Node) String() (output string) {
 	indents := ""
 	for i := 0; i < n.indent; i++ {
 		indents += "\t"
 	}
-	extra := ""
-	l := len(n.content.ResourceRecords)
-	for _, record := range n.content.ResourceRecords[:l-1] {
-		extra += fmt.Sprintf("%v, ", *record.Value)
+	extra := strings.Join(recordValues(n.content), ", ")
+	if n.IsCrossZone() {
+		extra += fmt.Sprintf(" (zone %s)", n.zone)
+	}
+	for _, resource := range n.resources {
+		extra += fmt.Sprintf(" [%s]", resource)
 	}
-	extra += *n.content.ResourceRecords[l-1].Value
 	output = fmt.Sprintf(
 		"%v%v %v %v\n",
 		indents,
@@ -48,3 +70,28 @@ func (n *Node) String() (output string) {
 	}
 	return
 }
+
+// Tree is a Node, and its children, in a form suitable for encoding.
+type Tree struct {
+	Name      string     `json:"name"`
+	Type      string     `json:"type"`
+	Values    []string   `json:"values"`
+	Zone      string     `json:"zone,omitempty"`
+	Resources []Resource `json:"resources,omitempty"`
+	Children  []Tree     `json:"children,omitempty"`
+}
+
+// Tree returns the Tree rooted at n.
+func (n *Node) Tree() Tree {
+	out := Tree{
+		Name:      *n.content.Name,
+		Type:      *n.content.Type,
+		Values:    recordValues(n.content),
+		Zone:      n.zone,
+		Resources: n.resources,
+	}
+	for _, child := range n.children {
+		out.Children = append(out.Children, child.Tree())
+	}
+	return out
+}
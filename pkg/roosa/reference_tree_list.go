@@ -1,8 +1,10 @@
 package roosa
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -40,17 +42,17 @@ func GetResourceRecordSet(
 }
 
 // GetZoneID returns a string containing the ZoneID for use in further API
-// actions
-func GetZoneID(zoneName string, svc route53iface.Route53API) (zoneID string) {
-	params := &route53.ListHostedZonesByNameInput{
-		DNSName:  aws.String(zoneName),
-		MaxItems: aws.String("100"),
-	}
-	resp, err := svc.ListHostedZonesByName(params)
+// actions. It fails if there is no hosted zone called zoneName.
+func GetZoneID(zoneName string, svc route53iface.Route53API) (zoneID string, err error) {
+	zones, err := findZones(zoneName, svc)
 	if err != nil {
-		log.Println(err.Error())
+		return
+	}
+	if len(zones) == 0 {
+		err = fmt.Errorf("Hosted zone %s not found", zoneName)
+		return
 	}
-	zoneID = *resp.HostedZones[0].Id
+	zoneID = *zones[0].Id
 	return
 }
 
@@ -73,10 +75,12 @@ func FilterResourceRecords(
 }
 
 // ReferenceTreeList is a type representing the reference trees for a list of
-// DNS records, explicitly A, AAAA, and CNAME records.
+// DNS records, explicitly A, AAAA, and CNAME records, including aliases.
 type ReferenceTreeList struct {
-	records []*route53.ResourceRecordSet
-	lookup  map[string][]*Node
+	records   []*route53.ResourceRecordSet
+	zones     zoneIndex
+	resources Resources
+	lookup    map[string][]*Node
 }
 
 var recordTypes = []string{
@@ -105,6 +109,24 @@ func NewReferenceTreeList(
 	}
 }
 
+// NewZoneReferenceTreeList is the constructor for ReferenceTreeList
+// covering several zones, so references into sibling zones are resolved
+// instead of being out of domain. Nodes referencing a record in another
+// zone show their own zone.
+func NewZoneReferenceTreeList(zones []Zone) *ReferenceTreeList {
+	records, index := indexZones(zones)
+	rtl := NewReferenceTreeList(records)
+	rtl.zones = index
+	return rtl
+}
+
+// SetResources makes nodes show the AWS resources behind their values,
+// taken from resources.
+func (rtl *ReferenceTreeList) SetResources(resources Resources) {
+	rtl.resources = resources
+	rtl.lookup = nil
+}
+
 // GetReferenceTrees builds and returns the reference trees among the
 // ReferenceTreeList records attribute.
 func (rtl *ReferenceTreeList) GetReferenceTrees() map[string][]*Node {
@@ -127,14 +149,41 @@ func (rtl *ReferenceTreeList) String() (output string) {
 	return
 }
 
+// Trees returns the reference trees sorted by the name of their roots.
+func (rtl *ReferenceTreeList) Trees() (out []Tree) {
+	if rtl.lookup == nil {
+		rtl.GetReferenceTrees()
+	}
+	for _, tree := range rtl.lookup {
+		for _, node := range tree {
+			out = append(out, node.Tree())
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return
+}
+
+// JSON returns the reference trees as a JSON document.
+func (rtl *ReferenceTreeList) JSON() (string, error) {
+	out, err := json.MarshalIndent(rtl.Trees(), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out) + "\n", nil
+}
+
 // fill fills the referral lookup table with the base records.
 func (rtl *ReferenceTreeList) fill() {
 	rtl.lookup = map[string][]*Node{}
 	for _, val := range rtl.records {
 		node := &Node{
-			content: val,
+			content:   val,
+			zone:      rtl.zones[val],
+			resources: rtl.resources.Lookup(val),
 		}
-		name := strings.TrimSuffix(*val.Name, ".")
+		name := normalizeName(*val.Name)
 		rtl.lookup[name] = append(rtl.lookup[name], node)
 	}
 	log.Printf("Added %d records to Lookup\n", len(rtl.lookup))
@@ -152,15 +201,34 @@ func (rtl *ReferenceTreeList) clean() {
 	}
 }
 
+// reference returns the name a CNAME or alias record points to.
+func reference(rrs *route53.ResourceRecordSet) (string, bool) {
+	for _, dep := range Dependencies(rrs) {
+		if dep.Kind == DependencyCNAME || dep.Kind == DependencyAlias {
+			return dep.Target, true
+		}
+	}
+	return "", false
+}
+
 // compact modifies the referral lookup table finding children and
 // roots, and relating these appropriately.
 func (rtl *ReferenceTreeList) compact() {
 	for name, nodes := range rtl.lookup {
 		for _, node := range nodes {
-			value := *node.content.ResourceRecords[0].Value
-			if *node.content.Type == "CNAME" {
+			value := strings.Join(recordValues(node.content), ", ")
+			if target, ok := reference(node.content); ok {
+				value = target
 				if parents, ok := rtl.lookup[value]; ok {
 					for _, parent := range parents {
+						if node.reaches(parent) {
+							log.Printf(
+								"%v (%v) closes a loop\n",
+								name,
+								value,
+							)
+							continue
+						}
 						log.Printf(
 							"%v (%v) has Parent %v\n",
 							name,
@@ -172,6 +240,13 @@ func (rtl *ReferenceTreeList) compact() {
 							node,
 						)
 						node.parent = parent
+						if node.IsCrossZone() {
+							log.Printf(
+								"%v references zone %v\n",
+								name,
+								parent.zone,
+							)
+						}
 					}
 				} else {
 					log.Printf(
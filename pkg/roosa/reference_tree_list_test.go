@@ -1,6 +1,7 @@
 package roosa
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
@@ -198,12 +199,15 @@ func TestGetZoneID(t *testing.T) {
 	mockSvc := &mockRoute53Client{}
 	for _, s := range grrstest {
 		t.Run(s, func(t *testing.T) {
-			out := GetZoneID(s, mockSvc)
-			if out != s {
+			out, err := GetZoneID(s, mockSvc)
+			if err != nil || out != s {
 				t.Error("Response doesn't match")
 			}
 		})
 	}
+	if _, err := GetZoneID("test", &zonesRoute53Client{}); err == nil {
+		t.Error("Expected error for missing zone")
+	}
 }
 
 var recordsContents = []string{
@@ -272,3 +276,28 @@ func TestReferenceTreeListString(t *testing.T) {
 		)
 	}
 }
+
+func TestReferenceTreeListJSON(t *testing.T) {
+	rtl := NewReferenceTreeList(generateRoute53RRS())
+	out, err := rtl.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var trees []Tree
+	if err := json.Unmarshal([]byte(out), &trees); err != nil {
+		t.Fatal(err)
+	}
+	if len(trees) != 5 || trees[1].Name != "multiple-a.example.com." {
+		t.Fatalf("Unexpected trees %v", trees)
+	}
+	root := trees[2]
+	if root.Name != "root.example.com." || len(root.Children) != 3 {
+		t.Errorf("Unexpected tree %v", root)
+	}
+	for _, child := range root.Children {
+		if child.Name == "root-son.example.com." &&
+			(len(child.Children) != 1 || child.Children[0].Name != "root-grandson.example.com.") {
+			t.Errorf("Unexpected children of %v", child)
+		}
+	}
+}
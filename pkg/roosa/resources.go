@@ -0,0 +1,428 @@
+package roosa
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudfront"
+	"github.com/aws/aws-sdk-go/service/cloudfront/cloudfrontiface"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elb/elbiface"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// Types of AWS resources records can point to.
+const (
+	ResourceInstance     = "ec2-instance"
+	ResourceLoadBalancer = "elb"
+	ResourceDistribution = "cloudfront"
+	ResourceBucket       = "s3-bucket"
+	ResourceDBInstance   = "rds-instance"
+)
+
+// Resource is an AWS resource behind a DNS record.
+type Resource struct {
+	Type  string            `json:"type"`
+	ID    string            `json:"id"`
+	State string            `json:"state,omitempty"`
+	Tags  map[string]string `json:"tags,omitempty"`
+}
+
+// String method for Resource gets a String to be printed.
+func (r Resource) String() string {
+	fields := []string{r.Type, r.ID}
+	if r.State != "" {
+		fields = append(fields, r.State)
+	}
+	keys := make([]string, 0, len(r.Tags))
+	for key := range r.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fields = append(fields, fmt.Sprintf("%s=%s", key, r.Tags[key]))
+	}
+	return strings.Join(fields, " ")
+}
+
+// Resources indexes AWS resources by the addresses and DNS names they
+// are reachable at. Buckets are indexed by name.
+type Resources map[string][]Resource
+
+// bucketKey returns the key of a bucket in Resources, so bucket names
+// can't be mistaken for DNS names.
+func bucketKey(bucket string) string {
+	return "s3:" + bucket
+}
+
+func (r Resources) add(key string, resource Resource) {
+	if key != "" {
+		r[normalizeName(key)] = append(r[normalizeName(key)], resource)
+	}
+}
+
+// s3BucketEndpoint matches the global, regional and website endpoints
+// of buckets, capturing the bucket.
+var s3BucketEndpoint = regexp.MustCompile(
+	`^(.+)\.s3(-website)?([.-][a-z0-9-]+)?\.amazonaws\.com$`,
+)
+
+// Target returns the resources reachable at target, an address or a
+// DNS name. Bucket endpoints are resolved to their bucket, and ELB
+// names to their load balancer, with or without "dualstack.".
+func (r Resources) Target(target string) []Resource {
+	target = normalizeName(target)
+	if match := s3BucketEndpoint.FindStringSubmatch(target); match != nil {
+		return r[bucketKey(match[1])]
+	}
+	return r[strings.TrimPrefix(target, "dualstack.")]
+}
+
+// Lookup returns the resources behind the values of rrs. Aliases to
+// S3 website endpoints are resolved to the bucket named as the record.
+func (r Resources) Lookup(rrs *route53.ResourceRecordSet) (out []Resource) {
+	for _, dep := range Dependencies(rrs) {
+		if s3WebsiteEndpoint.MatchString(normalizeName(dep.Target)) {
+			out = append(out, r[bucketKey(normalizeName(aws.StringValue(rrs.Name)))]...)
+			continue
+		}
+		out = append(out, r.Target(dep.Target)...)
+	}
+	return
+}
+
+// ResourceFinder collects the resources in an account which records
+// can point to. Services left nil are skipped.
+type ResourceFinder struct {
+	EC2        ec2iface.EC2API
+	ELB        elbiface.ELBAPI
+	ELBV2      elbv2iface.ELBV2API
+	CloudFront cloudfrontiface.CloudFrontAPI
+	S3         s3iface.S3API
+	RDS        rdsiface.RDSAPI
+}
+
+// GlobalRegion is the region global services, like CloudFront or the
+// listing of S3 buckets, are called in.
+const GlobalRegion = "us-east-1"
+
+// NewResourceFinders returns a ResourceFinder for the global services,
+// CloudFront and S3, and one for the regional services in each of the
+// regions.
+func NewResourceFinders(sess *session.Session, regions ...string) []*ResourceFinder {
+	global := aws.NewConfig().WithRegion(GlobalRegion)
+	out := []*ResourceFinder{
+		{
+			CloudFront: cloudfront.New(sess, global),
+			S3:         s3.New(sess, global),
+		},
+	}
+	for _, region := range regions {
+		config := aws.NewConfig().WithRegion(region)
+		out = append(out, &ResourceFinder{
+			EC2:   ec2.New(sess, config),
+			ELB:   elb.New(sess, config),
+			ELBV2: elbv2.New(sess, config),
+			RDS:   rds.New(sess, config),
+		})
+	}
+	return out
+}
+
+// FindResources returns the resources found by all the finders.
+func FindResources(finders ...*ResourceFinder) (Resources, error) {
+	out := make(Resources)
+	for _, finder := range finders {
+		resources, err := finder.Find()
+		if err != nil {
+			return nil, err
+		}
+		for key, list := range resources {
+			out[key] = append(out[key], list...)
+		}
+	}
+	return out, nil
+}
+
+// Find returns the EC2 instances, load balancers, CloudFront
+// distributions, S3 buckets and RDS instances in the account.
+func (f *ResourceFinder) Find() (Resources, error) {
+	out := make(Resources)
+	finders := []struct {
+		enabled bool
+		find    func(Resources) error
+	}{
+		{f.EC2 != nil, f.instances},
+		{f.ELB != nil, f.loadBalancers},
+		{f.ELBV2 != nil, f.loadBalancersV2},
+		{f.CloudFront != nil, f.distributions},
+		{f.S3 != nil, f.buckets},
+		{f.RDS != nil, f.dbInstances},
+	}
+	for _, finder := range finders {
+		if !finder.enabled {
+			continue
+		}
+		if err := finder.find(out); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// instances adds EC2 instances by their addresses and DNS names.
+func (f *ResourceFinder) instances(out Resources) error {
+	return f.EC2.DescribeInstancesPages(
+		&ec2.DescribeInstancesInput{},
+		func(page *ec2.DescribeInstancesOutput, last bool) bool {
+			for _, reservation := range page.Reservations {
+				for _, instance := range reservation.Instances {
+					resource := Resource{
+						Type: ResourceInstance,
+						ID:   aws.StringValue(instance.InstanceId),
+						Tags: make(map[string]string),
+					}
+					if instance.State != nil {
+						resource.State = aws.StringValue(instance.State.Name)
+					}
+					for _, tag := range instance.Tags {
+						resource.Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+					}
+					out.add(aws.StringValue(instance.PrivateIpAddress), resource)
+					out.add(aws.StringValue(instance.PublicIpAddress), resource)
+					out.add(aws.StringValue(instance.PrivateDnsName), resource)
+					out.add(aws.StringValue(instance.PublicDnsName), resource)
+				}
+			}
+			return true
+		},
+	)
+}
+
+// chunks splits list in slices of at most size elements, as accepted
+// by the APIs describing tags.
+func chunks(list []*string, size int) (out [][]*string) {
+	for len(list) > size {
+		out = append(out, list[:size])
+		list = list[size:]
+	}
+	if len(list) > 0 {
+		out = append(out, list)
+	}
+	return
+}
+
+// loadBalancers adds Classic Load Balancers by their DNS names.
+func (f *ResourceFinder) loadBalancers(out Resources) error {
+	balancers := make(map[string]*Resource)
+	names := make(map[string]string)
+	var ids []*string
+	err := f.ELB.DescribeLoadBalancersPages(
+		&elb.DescribeLoadBalancersInput{},
+		func(page *elb.DescribeLoadBalancersOutput, last bool) bool {
+			for _, lb := range page.LoadBalancerDescriptions {
+				id := aws.StringValue(lb.LoadBalancerName)
+				balancers[id] = &Resource{
+					Type: ResourceLoadBalancer,
+					ID:   id,
+					Tags: make(map[string]string),
+				}
+				names[id] = aws.StringValue(lb.DNSName)
+				ids = append(ids, lb.LoadBalancerName)
+			}
+			return true
+		},
+	)
+	if err != nil {
+		return err
+	}
+	for _, chunk := range chunks(ids, 20) {
+		tags, err := f.ELB.DescribeTags(&elb.DescribeTagsInput{
+			LoadBalancerNames: chunk,
+		})
+		if err != nil {
+			return err
+		}
+		for _, description := range tags.TagDescriptions {
+			lb, ok := balancers[aws.StringValue(description.LoadBalancerName)]
+			if !ok {
+				continue
+			}
+			for _, tag := range description.Tags {
+				lb.Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+			}
+		}
+	}
+	for id, lb := range balancers {
+		out.add(names[id], *lb)
+	}
+	return nil
+}
+
+// loadBalancersV2 adds Application and Network Load Balancers by their
+// DNS names.
+func (f *ResourceFinder) loadBalancersV2(out Resources) error {
+	balancers := make(map[string]*Resource)
+	names := make(map[string]string)
+	var arns []*string
+	err := f.ELBV2.DescribeLoadBalancersPages(
+		&elbv2.DescribeLoadBalancersInput{},
+		func(page *elbv2.DescribeLoadBalancersOutput, last bool) bool {
+			for _, lb := range page.LoadBalancers {
+				arn := aws.StringValue(lb.LoadBalancerArn)
+				balancers[arn] = &Resource{
+					Type: ResourceLoadBalancer,
+					ID:   aws.StringValue(lb.LoadBalancerName),
+					Tags: make(map[string]string),
+				}
+				if lb.State != nil {
+					balancers[arn].State = aws.StringValue(lb.State.Code)
+				}
+				names[arn] = aws.StringValue(lb.DNSName)
+				arns = append(arns, lb.LoadBalancerArn)
+			}
+			return true
+		},
+	)
+	if err != nil {
+		return err
+	}
+	for _, chunk := range chunks(arns, 20) {
+		tags, err := f.ELBV2.DescribeTags(&elbv2.DescribeTagsInput{
+			ResourceArns: chunk,
+		})
+		if err != nil {
+			return err
+		}
+		for _, description := range tags.TagDescriptions {
+			lb, ok := balancers[aws.StringValue(description.ResourceArn)]
+			if !ok {
+				continue
+			}
+			for _, tag := range description.Tags {
+				lb.Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+			}
+		}
+	}
+	for arn, lb := range balancers {
+		out.add(names[arn], *lb)
+	}
+	return nil
+}
+
+// distributions adds CloudFront distributions by their DNS names.
+func (f *ResourceFinder) distributions(out Resources) error {
+	var summaries []*cloudfront.DistributionSummary
+	err := f.CloudFront.ListDistributionsPages(
+		&cloudfront.ListDistributionsInput{},
+		func(page *cloudfront.ListDistributionsOutput, last bool) bool {
+			if page.DistributionList != nil {
+				summaries = append(summaries, page.DistributionList.Items...)
+			}
+			return true
+		},
+	)
+	if err != nil {
+		return err
+	}
+	for _, distribution := range summaries {
+		resource := Resource{
+			Type:  ResourceDistribution,
+			ID:    aws.StringValue(distribution.Id),
+			State: aws.StringValue(distribution.Status),
+			Tags:  make(map[string]string),
+		}
+		tags, err := f.CloudFront.ListTagsForResource(&cloudfront.ListTagsForResourceInput{
+			Resource: distribution.ARN,
+		})
+		if err != nil {
+			return err
+		}
+		if tags.Tags != nil {
+			for _, tag := range tags.Tags.Items {
+				resource.Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+			}
+		}
+		out.add(aws.StringValue(distribution.DomainName), resource)
+	}
+	return nil
+}
+
+// buckets adds S3 buckets by their names. Buckets without tags, or
+// whose tags can't be read, are added without them.
+func (f *ResourceFinder) buckets(out Resources) error {
+	buckets, err := f.S3.ListBuckets(&s3.ListBucketsInput{})
+	if err != nil {
+		return err
+	}
+	for _, bucket := range buckets.Buckets {
+		name := aws.StringValue(bucket.Name)
+		resource := Resource{
+			Type: ResourceBucket,
+			ID:   name,
+			Tags: make(map[string]string),
+		}
+		tags, err := f.S3.GetBucketTagging(&s3.GetBucketTaggingInput{
+			Bucket: bucket.Name,
+		})
+		if err != nil {
+			log.Printf("Couldn't get tags of bucket %s: %s\n", name, err)
+		} else {
+			for _, tag := range tags.TagSet {
+				resource.Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+			}
+		}
+		out.add(bucketKey(name), resource)
+	}
+	return nil
+}
+
+// dbInstances adds RDS instances by their endpoint addresses.
+func (f *ResourceFinder) dbInstances(out Resources) error {
+	var instances []*rds.DBInstance
+	err := f.RDS.DescribeDBInstancesPages(
+		&rds.DescribeDBInstancesInput{},
+		func(page *rds.DescribeDBInstancesOutput, last bool) bool {
+			instances = append(instances, page.DBInstances...)
+			return true
+		},
+	)
+	if err != nil {
+		return err
+	}
+	for _, instance := range instances {
+		if instance.Endpoint == nil {
+			continue
+		}
+		resource := Resource{
+			Type:  ResourceDBInstance,
+			ID:    aws.StringValue(instance.DBInstanceIdentifier),
+			State: aws.StringValue(instance.DBInstanceStatus),
+			Tags:  make(map[string]string),
+		}
+		tags, err := f.RDS.ListTagsForResource(&rds.ListTagsForResourceInput{
+			ResourceName: instance.DBInstanceArn,
+		})
+		if err != nil {
+			return err
+		}
+		for _, tag := range tags.TagList {
+			resource.Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+		out.add(aws.StringValue(instance.Endpoint.Address), resource)
+	}
+	return nil
+}
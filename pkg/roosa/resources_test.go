@@ -0,0 +1,319 @@
+package roosa
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudfront"
+	"github.com/aws/aws-sdk-go/service/cloudfront/cloudfrontiface"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elb/elbiface"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+func (m *mockEC2Client) DescribeInstancesPages(
+	params *ec2.DescribeInstancesInput,
+	f func(*ec2.DescribeInstancesOutput, bool) bool,
+) error {
+	f(&ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{{
+			Instances: []*ec2.Instance{{
+				InstanceId:       aws.String("i-1234"),
+				PrivateIpAddress: aws.String("10.0.0.1"),
+				PublicIpAddress:  aws.String("198.51.100.1"),
+				State:            &ec2.InstanceState{Name: aws.String("running")},
+				Tags: []*ec2.Tag{
+					{Key: aws.String("Name"), Value: aws.String("web")},
+				},
+			}},
+		}},
+	}, true)
+	return nil
+}
+
+type mockELBClient struct {
+	elbiface.ELBAPI
+}
+
+func (m *mockELBClient) DescribeLoadBalancersPages(
+	params *elb.DescribeLoadBalancersInput,
+	f func(*elb.DescribeLoadBalancersOutput, bool) bool,
+) error {
+	f(&elb.DescribeLoadBalancersOutput{
+		LoadBalancerDescriptions: []*elb.LoadBalancerDescription{{
+			LoadBalancerName: aws.String("classic"),
+			DNSName:          aws.String("classic-1.us-east-1.elb.amazonaws.com"),
+		}},
+	}, true)
+	return nil
+}
+
+func (m *mockELBClient) DescribeTags(
+	params *elb.DescribeTagsInput,
+) (*elb.DescribeTagsOutput, error) {
+	return &elb.DescribeTagsOutput{
+		TagDescriptions: []*elb.TagDescription{{
+			LoadBalancerName: aws.String("classic"),
+			Tags: []*elb.Tag{
+				{Key: aws.String("env"), Value: aws.String("prod")},
+			},
+		}},
+	}, nil
+}
+
+type mockELBV2Client struct {
+	elbv2iface.ELBV2API
+}
+
+func (m *mockELBV2Client) DescribeLoadBalancersPages(
+	params *elbv2.DescribeLoadBalancersInput,
+	f func(*elbv2.DescribeLoadBalancersOutput, bool) bool,
+) error {
+	f(&elbv2.DescribeLoadBalancersOutput{
+		LoadBalancers: []*elbv2.LoadBalancer{{
+			LoadBalancerArn:  aws.String("arn:alb"),
+			LoadBalancerName: aws.String("alb"),
+			DNSName:          aws.String("alb-1.us-east-1.elb.amazonaws.com"),
+			State:            &elbv2.LoadBalancerState{Code: aws.String("active")},
+		}},
+	}, true)
+	return nil
+}
+
+func (m *mockELBV2Client) DescribeTags(
+	params *elbv2.DescribeTagsInput,
+) (*elbv2.DescribeTagsOutput, error) {
+	return &elbv2.DescribeTagsOutput{}, nil
+}
+
+type mockCloudFrontClient struct {
+	cloudfrontiface.CloudFrontAPI
+}
+
+func (m *mockCloudFrontClient) ListDistributionsPages(
+	params *cloudfront.ListDistributionsInput,
+	f func(*cloudfront.ListDistributionsOutput, bool) bool,
+) error {
+	f(&cloudfront.ListDistributionsOutput{
+		DistributionList: &cloudfront.DistributionList{
+			Items: []*cloudfront.DistributionSummary{{
+				ARN:        aws.String("arn:cdn"),
+				Id:         aws.String("E1234"),
+				DomainName: aws.String("d111111abcdef8.cloudfront.net"),
+				Status:     aws.String("Deployed"),
+			}},
+		},
+	}, true)
+	return nil
+}
+
+func (m *mockCloudFrontClient) ListTagsForResource(
+	params *cloudfront.ListTagsForResourceInput,
+) (*cloudfront.ListTagsForResourceOutput, error) {
+	return &cloudfront.ListTagsForResourceOutput{}, nil
+}
+
+type mockS3Client struct {
+	s3iface.S3API
+}
+
+func (m *mockS3Client) ListBuckets(
+	params *s3.ListBucketsInput,
+) (*s3.ListBucketsOutput, error) {
+	return &s3.ListBucketsOutput{
+		Buckets: []*s3.Bucket{
+			{Name: aws.String("static.example.com")},
+			{Name: aws.String("assets")},
+		},
+	}, nil
+}
+
+func (m *mockS3Client) GetBucketTagging(
+	params *s3.GetBucketTaggingInput,
+) (*s3.GetBucketTaggingOutput, error) {
+	if aws.StringValue(params.Bucket) == "assets" {
+		return nil, errors.New("NoSuchTagSet")
+	}
+	return &s3.GetBucketTaggingOutput{
+		TagSet: []*s3.Tag{
+			{Key: aws.String("team"), Value: aws.String("web")},
+		},
+	}, nil
+}
+
+type mockRDSClient struct {
+	rdsiface.RDSAPI
+}
+
+func (m *mockRDSClient) DescribeDBInstancesPages(
+	params *rds.DescribeDBInstancesInput,
+	f func(*rds.DescribeDBInstancesOutput, bool) bool,
+) error {
+	f(&rds.DescribeDBInstancesOutput{
+		DBInstances: []*rds.DBInstance{
+			{
+				DBInstanceIdentifier: aws.String("db"),
+				DBInstanceArn:        aws.String("arn:db"),
+				DBInstanceStatus:     aws.String("available"),
+				Endpoint: &rds.Endpoint{
+					Address: aws.String("db.abc.us-east-1.rds.amazonaws.com"),
+				},
+			},
+			{
+				DBInstanceIdentifier: aws.String("creating"),
+				DBInstanceStatus:     aws.String("creating"),
+			},
+		},
+	}, true)
+	return nil
+}
+
+func (m *mockRDSClient) ListTagsForResource(
+	params *rds.ListTagsForResourceInput,
+) (*rds.ListTagsForResourceOutput, error) {
+	return &rds.ListTagsForResourceOutput{}, nil
+}
+
+func newResourceFinder() *ResourceFinder {
+	return &ResourceFinder{
+		EC2:        &mockEC2Client{},
+		ELB:        &mockELBClient{},
+		ELBV2:      &mockELBV2Client{},
+		CloudFront: &mockCloudFrontClient{},
+		S3:         &mockS3Client{},
+		RDS:        &mockRDSClient{},
+	}
+}
+
+func TestResourceFinderFind(t *testing.T) {
+	resources, err := newResourceFinder().Find()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []struct {
+		rrs      *route53.ResourceRecordSet
+		expected []string
+	}{
+		{
+			rrs:      newRRS("web.example.com.", "A", "10.0.0.1", "198.51.100.1"),
+			expected: []string{"ec2-instance i-1234 running Name=web", "ec2-instance i-1234 running Name=web"},
+		},
+		{
+			rrs:      newRRS("old.example.com.", "CNAME", "classic-1.us-east-1.elb.amazonaws.com"),
+			expected: []string{"elb classic env=prod"},
+		},
+		{
+			rrs:      newAlias("example.com.", "A", "dualstack.alb-1.us-east-1.elb.amazonaws.com."),
+			expected: []string{"elb alb active"},
+		},
+		{
+			rrs:      newAlias("cdn.example.com.", "A", "d111111abcdef8.cloudfront.net."),
+			expected: []string{"cloudfront E1234 Deployed"},
+		},
+		{
+			rrs:      newAlias("static.example.com.", "A", "s3-website-us-east-1.amazonaws.com."),
+			expected: []string{"s3-bucket static.example.com team=web"},
+		},
+		{
+			rrs:      newRRS("assets.example.com.", "CNAME", "assets.s3.amazonaws.com"),
+			expected: []string{"s3-bucket assets"},
+		},
+		{
+			rrs:      newRRS("files.example.com.", "CNAME", "assets.s3.eu-west-1.amazonaws.com"),
+			expected: []string{"s3-bucket assets"},
+		},
+		{
+			rrs:      newRRS("site.example.com.", "CNAME", "assets.s3-website-us-east-1.amazonaws.com"),
+			expected: []string{"s3-bucket assets"},
+		},
+		{
+			rrs: newRRS("cache.example.com.", "CNAME", "assets.s3cache.example.net"),
+		},
+		{
+			rrs:      newRRS("db.example.com.", "CNAME", "db.abc.us-east-1.rds.amazonaws.com."),
+			expected: []string{"rds-instance db available"},
+		},
+		{
+			rrs: newRRS("other.example.com.", "A", "10.0.0.9"),
+		},
+	}
+	for _, tc := range data {
+		var out []string
+		for _, resource := range resources.Lookup(tc.rrs) {
+			out = append(out, resource.String())
+		}
+		if strings.Join(out, "\n") != strings.Join(tc.expected, "\n") {
+			t.Errorf("Expected %v for %s, found %v", tc.expected, *tc.rrs.Name, out)
+		}
+	}
+}
+
+func TestFindResources(t *testing.T) {
+	resources, err := FindResources(
+		&ResourceFinder{S3: &mockS3Client{}},
+		&ResourceFinder{EC2: &mockEC2Client{region: "us-east-1"}},
+		&ResourceFinder{RDS: &mockRDSClient{}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{
+		"10.0.0.1",
+		bucketKey("assets"),
+		"db.abc.us-east-1.rds.amazonaws.com",
+	} {
+		if len(resources[key]) != 1 {
+			t.Errorf("Unexpected resources for %s: %v", key, resources[key])
+		}
+	}
+}
+
+func TestResourcesDisplay(t *testing.T) {
+	resources, err := (&ResourceFinder{
+		EC2: &mockEC2Client{},
+		S3:  &mockS3Client{},
+	}).Find()
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := []*route53.ResourceRecordSet{
+		newRRS("web.example.com.", "A", "10.0.0.1"),
+		newRRS("www.example.com.", "CNAME", "web.example.com"),
+		newAlias("static.example.com.", "A", "s3-website-us-east-1.amazonaws.com."),
+	}
+	rtl := NewReferenceTreeList(records)
+	rtl.SetResources(resources)
+	output := rtl.String()
+	expected := "web.example.com. A 10.0.0.1 [ec2-instance i-1234 running Name=web]\n" +
+		"\twww.example.com. CNAME web.example.com\n"
+	if !strings.Contains(output, expected) {
+		t.Errorf("%q not found in:\n%s", expected, output)
+	}
+	g := NewGraph(records)
+	g.SetResources(resources)
+	for _, node := range g.Nodes {
+		var expected []Resource
+		switch node.ID {
+		case "10.0.0.1":
+			expected = resources["10.0.0.1"]
+		case "static.example.com":
+			expected = resources[bucketKey("static.example.com")]
+		}
+		if len(node.Resources) != len(expected) {
+			t.Errorf("Unexpected resources for %s: %v", node.ID, node.Resources)
+		}
+	}
+	mermaid := g.Mermaid()
+	if !strings.Contains(mermaid, "10.0.0.1<br/>ec2-instance i-1234 running Name=web") {
+		t.Errorf("Resource not found in Mermaid output:\n%s", mermaid)
+	}
+}
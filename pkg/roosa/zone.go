@@ -0,0 +1,88 @@
+package roosa
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+)
+
+// Zone is a Route53 hosted zone and its records.
+type Zone struct {
+	ID      string
+	Name    string
+	Records []*route53.ResourceRecordSet
+}
+
+// GetZones returns the hosted zones called names, with their records,
+// or all the hosted zones in the account if names is empty. Public and
+// private zones sharing a name are all returned.
+func GetZones(names []string, svc route53iface.Route53API) (out []Zone, err error) {
+	var zones []*route53.HostedZone
+	if len(names) == 0 {
+		err = svc.ListHostedZonesPages(
+			&route53.ListHostedZonesInput{},
+			func(page *route53.ListHostedZonesOutput, last bool) bool {
+				zones = append(zones, page.HostedZones...)
+				return true
+			},
+		)
+		if err != nil {
+			return
+		}
+	}
+	for _, name := range names {
+		var found []*route53.HostedZone
+		found, err = findZones(name, svc)
+		if err != nil {
+			return
+		}
+		if len(found) == 0 {
+			err = fmt.Errorf("Hosted zone %s not found", name)
+			return
+		}
+		zones = append(zones, found...)
+	}
+	for _, zone := range zones {
+		out = append(out, Zone{
+			ID:      aws.StringValue(zone.Id),
+			Name:    normalizeName(aws.StringValue(zone.Name)),
+			Records: GetResourceRecordSet(aws.StringValue(zone.Id), svc),
+		})
+	}
+	return
+}
+
+// findZones returns the hosted zones called name. The API lists zones
+// in order starting by name, so the first results may be other zones.
+func findZones(name string, svc route53iface.Route53API) (out []*route53.HostedZone, err error) {
+	resp, err := svc.ListHostedZonesByName(&route53.ListHostedZonesByNameInput{
+		DNSName:  aws.String(name),
+		MaxItems: aws.String("100"),
+	})
+	if err != nil {
+		return
+	}
+	for _, zone := range resp.HostedZones {
+		if normalizeName(aws.StringValue(zone.Name)) == normalizeName(name) {
+			out = append(out, zone)
+		}
+	}
+	return
+}
+
+// zoneIndex maps each record to the name of the zone it belongs to.
+type zoneIndex map[*route53.ResourceRecordSet]string
+
+// indexZones returns the records of all zones, and the zone of each.
+func indexZones(zones []Zone) (records []*route53.ResourceRecordSet, index zoneIndex) {
+	index = make(zoneIndex)
+	for _, zone := range zones {
+		for _, rrs := range zone.Records {
+			index[rrs] = zone.Name
+			records = append(records, rrs)
+		}
+	}
+	return
+}
@@ -0,0 +1,151 @@
+package roosa
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+var crossZones = []Zone{
+	{
+		ID:   "Z1",
+		Name: "example.com",
+		Records: []*route53.ResourceRecordSet{
+			newRRS("web.example.com.", "A", "10.0.0.1"),
+			newRRS("www.example.com.", "CNAME", "web.example.com"),
+		},
+	},
+	{
+		ID:   "Z2",
+		Name: "example.org",
+		Records: []*route53.ResourceRecordSet{
+			newRRS("www.example.org.", "CNAME", "web.example.com"),
+			newAlias("example.org.", "A", "www.example.com."),
+		},
+	},
+}
+
+// zonesRoute53Client lists hosted zones, sorted by name, each of them
+// with the same records.
+type zonesRoute53Client struct {
+	mockRoute53Client
+	zones []string
+}
+
+func (m *zonesRoute53Client) hostedZones(start string) (out []*route53.HostedZone) {
+	for _, name := range m.zones {
+		if name >= normalizeName(start) {
+			out = append(out, &route53.HostedZone{
+				Id:   aws.String("/hostedzone/" + name),
+				Name: aws.String(name + "."),
+			})
+		}
+	}
+	return
+}
+
+func (m *zonesRoute53Client) ListHostedZonesPages(
+	params *route53.ListHostedZonesInput,
+	f func(*route53.ListHostedZonesOutput, bool) bool,
+) error {
+	f(&route53.ListHostedZonesOutput{HostedZones: m.hostedZones("")}, true)
+	return nil
+}
+
+func (m *zonesRoute53Client) ListHostedZonesByName(
+	params *route53.ListHostedZonesByNameInput,
+) (*route53.ListHostedZonesByNameOutput, error) {
+	return &route53.ListHostedZonesByNameOutput{
+		HostedZones: m.hostedZones(aws.StringValue(params.DNSName)),
+	}, nil
+}
+
+func TestGetZones(t *testing.T) {
+	svc := &zonesRoute53Client{zones: []string{"a.com", "b.com", "c.com"}}
+	data := []struct {
+		names    []string
+		expected []string
+		err      bool
+	}{
+		{nil, []string{"a.com", "b.com", "c.com"}, false},
+		{[]string{"b.com"}, []string{"b.com"}, false},
+		{[]string{"c.com.", "a.com"}, []string{"c.com", "a.com"}, false},
+		{[]string{"b.org"}, nil, true},
+	}
+	for _, tc := range data {
+		zones, err := GetZones(tc.names, svc)
+		if (err != nil) != tc.err {
+			t.Errorf("Unexpected error for %v: %v", tc.names, err)
+			continue
+		}
+		var names []string
+		for _, zone := range zones {
+			names = append(names, zone.Name)
+			if len(zone.Records) != len(ResourceRecordSetList) {
+				t.Errorf("Unexpected records for %s: %v", zone.Name, zone.Records)
+			}
+		}
+		if strings.Join(names, ",") != strings.Join(tc.expected, ",") {
+			t.Errorf("Expected zones %v for %v, found %v", tc.expected, tc.names, names)
+		}
+	}
+}
+
+func TestZoneReferenceTreeList(t *testing.T) {
+	output := NewZoneReferenceTreeList(crossZones).String()
+	for _, expected := range []string{
+		"web.example.com. A 10.0.0.1\n",
+		"\twww.example.com. CNAME web.example.com\n",
+		"\t\texample.org. A ALIAS www.example.com. (zone example.org)\n",
+		"\twww.example.org. CNAME web.example.com (zone example.org)\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("%q not found in:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "www.example.com. CNAME web.example.com (zone") {
+		t.Errorf("Same zone reference marked as cross zone:\n%s", output)
+	}
+}
+
+func TestZoneGraph(t *testing.T) {
+	g := NewZoneGraph(crossZones)
+	for _, node := range g.Nodes {
+		if node.Kind == NodeRecord && !strings.HasSuffix(node.ID, node.Zone) {
+			t.Errorf("Node %v in wrong zone", node)
+		}
+	}
+	data := []GraphEdge{
+		{"www.example.com", "web.example.com", DependencyCNAME, false},
+		{"www.example.org", "web.example.com", DependencyCNAME, true},
+		{"example.org", "www.example.com", DependencyAlias, true},
+		{"web.example.com", "10.0.0.1", DependencyAddress, false},
+	}
+	for _, edge := range data {
+		found := false
+		for _, e := range g.Edges {
+			found = found || e == edge
+		}
+		if !found {
+			t.Errorf("Edge %v not found in %v", edge, g.Edges)
+		}
+	}
+	dot := g.DOT()
+	for _, expected := range []string{"cluster_example.org", "bold"} {
+		if !strings.Contains(dot, expected) {
+			t.Errorf("%q not found in DOT output:\n%s", expected, dot)
+		}
+	}
+	mermaid := g.Mermaid()
+	for _, expected := range []string{
+		"  subgraph z_example_org[\"example.org\"]\n",
+		"  n_www_example_org ==>|\"cname\"| n_web_example_com\n",
+		"  n_www_example_com -->|\"cname\"| n_web_example_com\n",
+	} {
+		if !strings.Contains(mermaid, expected) {
+			t.Errorf("%q not found in Mermaid output:\n%s", expected, mermaid)
+		}
+	}
+}